@@ -3,15 +3,30 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds all configuration settings
 type Config struct {
-	Scraper  ScraperConfig  `yaml:"scraper"`
-	Database DatabaseConfig `yaml:"database"`
-	Output   OutputConfig   `yaml:"output"`
+	Scraper    ScraperConfig    `yaml:"scraper"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Output     OutputConfig     `yaml:"output"`
+	Gazetteer  GazetteerConfig  `yaml:"gazetteer"`
+	Analytics  AnalyticsConfig  `yaml:"analytics"`
+	Alerts     AlertsConfig     `yaml:"alerts"`
+	Health     HealthConfig     `yaml:"health"`
+	Duplicates DuplicatesConfig `yaml:"duplicates"`
+	PriceModel PriceModelConfig `yaml:"price_model"`
+	PriceSweep PriceSweepConfig `yaml:"price_sweep"`
+	Segments   SegmentsConfig   `yaml:"segments"`
+	Comps      CompsConfig      `yaml:"comps"`
+	Watchlist  WatchlistConfig  `yaml:"watchlist"`
+	Discovery  DiscoveryConfig  `yaml:"discovery"`
 }
 
 type ScraperConfig struct {
@@ -25,8 +40,35 @@ type ScraperConfig struct {
 	RetryDelayMs      int    `yaml:"retry_delay_ms"`
 	Headless          bool   `yaml:"headless"`
 	TimeoutSeconds    int    `yaml:"timeout_seconds"`
+
+	// Pagination settings
+	PaginationMode       string         `yaml:"pagination_mode"` // "limited" (default) or "exhaust"
+	PageSize             int            `yaml:"page_size"`       // results Airbnb returns per search page
+	DefaultLocationQuota int            `yaml:"default_location_quota"`
+	LocationQuotas       map[string]int `yaml:"location_quotas"`
+
+	// Map-tiling crawl settings
+	TileSearchURL string `yaml:"tile_search_url"` // search URL the tile bounds are applied to
+	TileResultCap int    `yaml:"tile_result_cap"` // result count at which a tile is subdivided
+	TileMaxDepth  int    `yaml:"tile_max_depth"`  // maximum number of subdivisions
+
+	// Availability calendar settings
+	CalendarDays   int    `yaml:"calendar_days"`    // upcoming nights to fetch, 1-365
+	ScrapeCalendar bool   `yaml:"scrape_calendar"`  // also fetch calendars during regular scrapes
+	CalendarAPIKey string `yaml:"calendar_api_key"` // empty = read the web client key from the listing page
+
+	// Search page loading
+	ScrollMaxRounds    int `yaml:"scroll_max_rounds"`     // maximum scrolls per results page
+	NetworkIdleMs      int `yaml:"network_idle_ms"`       // quiet time that counts as network idle
+	CardReadyTimeoutMs int `yaml:"card_ready_timeout_ms"` // wait for every card to show a title and price
 }
 
+// Pagination modes
+const (
+	PaginationLimited = "limited"
+	PaginationExhaust = "exhaust"
+)
+
 type DatabaseConfig struct {
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
@@ -41,6 +83,139 @@ type OutputConfig struct {
 	JSONConsole bool   `yaml:"json_console"`
 }
 
+// GazetteerConfig points at the local GeoNames dump used to resolve locations
+type GazetteerConfig struct {
+	CitiesFile    string `yaml:"cities_file"`
+	RegionsFile   string `yaml:"regions_file"`
+	CountriesFile string `yaml:"countries_file"`
+}
+
+// AnalyticsConfig holds settings for the analytics reports
+type AnalyticsConfig struct {
+	PriceBuckets []float64 `yaml:"price_buckets"` // ascending lower edges of the price histogram buckets
+
+	// Bayesian top-rated ranking: a listing's rating is blended with RatingPriorMean
+	// as if it had RatingPriorWeight extra reviews at that rating
+	RatingPriorMean   float64 `yaml:"rating_prior_mean"` // 0 = mean rating of all rated listings
+	RatingPriorWeight float64 `yaml:"rating_prior_weight"`
+	MinReviews        int     `yaml:"min_reviews"` // listings with fewer reviews are not ranked
+
+	// Data quality: price outliers per location are found with "iqr" (Tukey fences,
+	// threshold = IQR multiplier) or "mad" (robust z-score, threshold = max |z|).
+	// Flagged listings are left out of reports unless IncludeFlagged is set.
+	OutlierMethod    string  `yaml:"outlier_method"`
+	OutlierThreshold float64 `yaml:"outlier_threshold"`
+	IncludeFlagged   bool    `yaml:"include_flagged"`
+
+	// Count each probable duplicate cluster as one listing
+	CountDuplicatesOnce bool `yaml:"count_duplicates_once"`
+}
+
+// AlertsConfig holds the alert rules evaluated after each scrape and where alerts are delivered
+type AlertsConfig struct {
+	WebhookURL string      `yaml:"webhook_url"` // receives a JSON POST per alert
+	SMTP       SMTPConfig  `yaml:"smtp"`
+	Rules      []AlertRule `yaml:"rules"`
+}
+
+// SMTPConfig holds the mail server alerts are sent through
+type SMTPConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// AlertRule is one user-defined alert condition
+type AlertRule struct {
+	Name      string  `yaml:"name"`
+	Type      string  `yaml:"type"`       // price_drop, new_listing or empty_location
+	Location  string  `yaml:"location"`   // only listings/locations containing this text, empty = all
+	Threshold float64 `yaml:"threshold"`  // price_drop: minimum drop in percent
+	MaxPrice  float64 `yaml:"max_price"`  // only listings priced at or below, 0 = no limit
+	MinRating float64 `yaml:"min_rating"` // only listings rated at or above, 0 = no limit
+}
+
+// Alert rule types
+const (
+	AlertPriceDrop     = "price_drop"
+	AlertNewListing    = "new_listing"
+	AlertEmptyLocation = "empty_location"
+)
+
+// HealthConfig holds the thresholds a scrape run is checked against
+type HealthConfig struct {
+	MinCardsPerPage      float64 `yaml:"min_cards_per_page"`
+	MinDetailSuccessRate float64 `yaml:"min_detail_success_rate"` // 0-1
+	MaxEmptyFieldShare   float64 `yaml:"max_empty_field_share"`   // 0-1, share of card/detail fields left empty
+	MinBaselineRatio     float64 `yaml:"min_baseline_ratio"`      // location count / median of recent runs
+	BaselineRuns         int     `yaml:"baseline_runs"`           // number of recent healthy runs in the baseline
+	FailOnBreach         bool    `yaml:"fail_on_breach"`          // exit non-zero when a threshold is breached
+	AlertOnBreach        bool    `yaml:"alert_on_breach"`         // send an alert through the alert channels
+}
+
+// DuplicatesConfig holds the thresholds for probable duplicate listings within a location
+type DuplicatesConfig struct {
+	TitleSimilarity   float64 `yaml:"title_similarity"`    // minimum title similarity, 0-1
+	PriceTolerance    float64 `yaml:"price_tolerance"`     // maximum relative price difference, 0-1
+	MaxDistanceMeters float64 `yaml:"max_distance_meters"` // listings with coordinates farther apart are never duplicates
+}
+
+// PriceModelConfig holds the settings of the fair-price ridge regression
+type PriceModelConfig struct {
+	Lambda           float64 `yaml:"lambda"`             // L2 penalty on the standardized coefficients
+	MinCategoryCount int     `yaml:"min_category_count"` // rarer locations/property types share one baseline
+	TestFraction     float64 `yaml:"test_fraction"`      // share of listings held out for MAE and R²
+}
+
+// PriceSweepConfig holds the grid of check-in dates and stay lengths a date sweep quotes
+type PriceSweepConfig struct {
+	CheckInWeekdays []string `yaml:"check_in_weekdays"` // e.g. ["friday"]; empty = every day
+	Weeks           int      `yaml:"weeks"`             // number of weeks ahead to sweep
+	StayLengths     []int    `yaml:"stay_lengths"`      // nights per stay
+	Adults          int      `yaml:"adults"`
+	MaxListings     int      `yaml:"max_listings"` // listings swept when a location is given
+}
+
+// SegmentsConfig holds the settings of the k-means market segmentation
+type SegmentsConfig struct {
+	K                int `yaml:"k"`                  // number of segments
+	MaxIterations    int `yaml:"max_iterations"`     // per k-means run
+	Restarts         int `yaml:"restarts"`           // runs from different seeds; the tightest one is kept
+	MinCategoryCount int `yaml:"min_category_count"` // rarer property types share one encoding
+}
+
+// CompsConfig holds the settings of the comparable-set search
+type CompsConfig struct {
+	Count         int     `yaml:"count"`           // comparable listings per target
+	MaxDistanceKm float64 `yaml:"max_distance_km"` // radius when both listings have coordinates
+}
+
+// WatchlistConfig holds the reference stay watched listings are priced for
+type WatchlistConfig struct {
+	CheckInDays int `yaml:"check_in_days"` // days from today to the check-in of the reference stay
+	Nights      int `yaml:"nights"`
+	Adults      int `yaml:"adults"`
+}
+
+// DiscoveryConfig selects which searches discovered on the homepage are crawled
+// Include and Exclude are case-insensitive regular expressions matched against a search's
+// name and its full path ("Beach > Florida > Miami"); a plain name matches itself.
+type DiscoveryConfig struct {
+	Kinds   []string `yaml:"kinds"`   // location, category and/or inspiration
+	Include []string `yaml:"include"` // when set, a search must match one of them
+	Exclude []string `yaml:"exclude"` // a search matching any of them is skipped
+}
+
+// Homepage discovery kinds
+const (
+	DiscoveryLocation    = "location"    // location links on the homepage
+	DiscoveryCategory    = "category"    // category tabs (Beachfront, Cabins...)
+	DiscoveryInspiration = "inspiration" // "Inspiration for future getaways" destinations
+)
+
 // Load reads and parses the config file
 func Load(filepath string) (*Config, error) {
 	data, err := os.ReadFile(filepath)
@@ -58,9 +233,264 @@ func Load(filepath string) (*Config, error) {
 		return nil, fmt.Errorf("scraper.url is required")
 	}
 
+	switch cfg.Scraper.PaginationMode {
+	case "":
+		cfg.Scraper.PaginationMode = PaginationLimited
+	case PaginationLimited, PaginationExhaust:
+	default:
+		return nil, fmt.Errorf("scraper.pagination_mode must be %q or %q", PaginationLimited, PaginationExhaust)
+	}
+
+	if cfg.Scraper.TimeoutSeconds <= 0 {
+		cfg.Scraper.TimeoutSeconds = 60
+	}
+
+	if cfg.Scraper.PageSize <= 0 {
+		cfg.Scraper.PageSize = 18 // Airbnb default
+	}
+
+	if cfg.Scraper.TileSearchURL == "" {
+		cfg.Scraper.TileSearchURL = strings.TrimSuffix(cfg.Scraper.BaseURL, "/") + "/s/homes"
+	}
+	if cfg.Scraper.TileResultCap <= 0 {
+		cfg.Scraper.TileResultCap = 270 // Airbnb stops returning results at 15 pages × 18
+	}
+	if cfg.Scraper.TileMaxDepth <= 0 {
+		cfg.Scraper.TileMaxDepth = 8
+	}
+
+	if cfg.Scraper.CalendarDays <= 0 {
+		cfg.Scraper.CalendarDays = 90
+	}
+	if cfg.Scraper.CalendarDays > 365 {
+		return nil, fmt.Errorf("scraper.calendar_days must be at most 365")
+	}
+
+	if cfg.Scraper.ScrollMaxRounds <= 0 {
+		cfg.Scraper.ScrollMaxRounds = 30
+	}
+	if cfg.Scraper.NetworkIdleMs <= 0 {
+		cfg.Scraper.NetworkIdleMs = 500
+	}
+	if cfg.Scraper.CardReadyTimeoutMs <= 0 {
+		cfg.Scraper.CardReadyTimeoutMs = 5000
+	}
+
+	if len(cfg.Analytics.PriceBuckets) == 0 {
+		cfg.Analytics.PriceBuckets = []float64{0, 50, 100, 150, 200, 300, 500, 1000}
+	}
+	if cfg.Analytics.RatingPriorWeight <= 0 {
+		cfg.Analytics.RatingPriorWeight = 10
+	}
+	switch cfg.Analytics.OutlierMethod {
+	case "", "iqr":
+		cfg.Analytics.OutlierMethod = "iqr"
+		if cfg.Analytics.OutlierThreshold <= 0 {
+			cfg.Analytics.OutlierThreshold = 1.5
+		}
+	case "mad":
+		if cfg.Analytics.OutlierThreshold <= 0 {
+			cfg.Analytics.OutlierThreshold = 3.5
+		}
+	default:
+		return nil, fmt.Errorf("analytics.outlier_method must be \"iqr\" or \"mad\"")
+	}
+	if !sort.Float64sAreSorted(cfg.Analytics.PriceBuckets) {
+		return nil, fmt.Errorf("analytics.price_buckets must be in ascending order")
+	}
+
+	if cfg.Health.MinCardsPerPage <= 0 {
+		cfg.Health.MinCardsPerPage = 10
+	}
+	if cfg.Health.MinDetailSuccessRate <= 0 {
+		cfg.Health.MinDetailSuccessRate = 0.8
+	}
+	if cfg.Health.MaxEmptyFieldShare <= 0 {
+		cfg.Health.MaxEmptyFieldShare = 0.3
+	}
+	if cfg.Health.MinBaselineRatio <= 0 {
+		cfg.Health.MinBaselineRatio = 0.5
+	}
+	if cfg.Health.BaselineRuns <= 0 {
+		cfg.Health.BaselineRuns = 5
+	}
+
+	if cfg.Duplicates.TitleSimilarity <= 0 {
+		cfg.Duplicates.TitleSimilarity = 0.8
+	}
+	if cfg.Duplicates.PriceTolerance <= 0 {
+		cfg.Duplicates.PriceTolerance = 0.15
+	}
+	if cfg.Duplicates.MaxDistanceMeters <= 0 {
+		cfg.Duplicates.MaxDistanceMeters = 150
+	}
+
+	if cfg.PriceModel.Lambda <= 0 {
+		cfg.PriceModel.Lambda = 1
+	}
+	if cfg.PriceModel.MinCategoryCount <= 0 {
+		cfg.PriceModel.MinCategoryCount = 3
+	}
+	if cfg.PriceModel.TestFraction <= 0 || cfg.PriceModel.TestFraction >= 1 {
+		cfg.PriceModel.TestFraction = 0.2
+	}
+
+	if cfg.PriceSweep.Weeks <= 0 {
+		cfg.PriceSweep.Weeks = 12
+	}
+	if len(cfg.PriceSweep.StayLengths) == 0 {
+		cfg.PriceSweep.StayLengths = []int{2, 3, 7}
+	}
+	for _, nights := range cfg.PriceSweep.StayLengths {
+		if nights <= 0 {
+			return nil, fmt.Errorf("price_sweep.stay_lengths must be positive")
+		}
+	}
+	if cfg.PriceSweep.Adults <= 0 {
+		cfg.PriceSweep.Adults = 2
+	}
+	if cfg.PriceSweep.MaxListings <= 0 {
+		cfg.PriceSweep.MaxListings = 10
+	}
+	if _, err := cfg.PriceSweep.Weekdays(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Segments.K <= 0 {
+		cfg.Segments.K = 5
+	}
+	if cfg.Segments.MaxIterations <= 0 {
+		cfg.Segments.MaxIterations = 100
+	}
+	if cfg.Segments.Restarts <= 0 {
+		cfg.Segments.Restarts = 5
+	}
+	if cfg.Segments.MinCategoryCount <= 0 {
+		cfg.Segments.MinCategoryCount = 3
+	}
+
+	if cfg.Comps.Count <= 0 {
+		cfg.Comps.Count = 10
+	}
+	if cfg.Comps.MaxDistanceKm <= 0 {
+		cfg.Comps.MaxDistanceKm = 3
+	}
+
+	if cfg.Watchlist.CheckInDays <= 0 {
+		cfg.Watchlist.CheckInDays = 14
+	}
+	if cfg.Watchlist.Nights <= 0 {
+		cfg.Watchlist.Nights = 2
+	}
+	if cfg.Watchlist.Adults <= 0 {
+		cfg.Watchlist.Adults = 2
+	}
+
+	if len(cfg.Discovery.Kinds) == 0 {
+		cfg.Discovery.Kinds = []string{DiscoveryLocation}
+	}
+	for _, kind := range cfg.Discovery.Kinds {
+		switch kind {
+		case DiscoveryLocation, DiscoveryCategory, DiscoveryInspiration:
+		default:
+			return nil, fmt.Errorf("discovery.kinds: unknown kind %q (use %q, %q or %q)",
+				kind, DiscoveryLocation, DiscoveryCategory, DiscoveryInspiration)
+		}
+	}
+	if _, _, err := cfg.Discovery.Patterns(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Alerts.SMTP.Host != "" && cfg.Alerts.SMTP.Port == 0 {
+		cfg.Alerts.SMTP.Port = 25
+	}
+	names := make(map[string]bool)
+	for i, rule := range cfg.Alerts.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("alerts.rules[%d] needs a name", i)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("alerts.rules: duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		switch rule.Type {
+		case AlertPriceDrop:
+			if rule.Threshold <= 0 {
+				return nil, fmt.Errorf("alerts.rules %q: price_drop needs a threshold in percent", rule.Name)
+			}
+		case AlertNewListing, AlertEmptyLocation:
+		default:
+			return nil, fmt.Errorf("alerts.rules %q: type must be %q, %q or %q",
+				rule.Name, AlertPriceDrop, AlertNewListing, AlertEmptyLocation)
+		}
+	}
+
 	return &cfg, nil
 }
 
+// IsExhaustive reports whether every page should be crawled until results run out
+func (c *ScraperConfig) IsExhaustive() bool {
+	return c.PaginationMode == PaginationExhaust
+}
+
+// QuotaFor returns the maximum number of listings to collect for a location
+// Returns 0 when the location has no quota
+func (c *ScraperConfig) QuotaFor(location string) int {
+	if quota, ok := c.LocationQuotas[location]; ok {
+		return quota
+	}
+	return c.DefaultLocationQuota
+}
+
+// Weekdays parses CheckInWeekdays
+// Returns every day of the week when none are configured
+func (c *PriceSweepConfig) Weekdays() ([]time.Weekday, error) {
+	if len(c.CheckInWeekdays) == 0 {
+		return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday,
+			time.Thursday, time.Friday, time.Saturday}, nil
+	}
+
+	weekdays := make([]time.Weekday, 0, len(c.CheckInWeekdays))
+	for _, name := range c.CheckInWeekdays {
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(name, day.String()) || strings.EqualFold(name, day.String()[:3]) {
+				weekdays = append(weekdays, day)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("price_sweep.check_in_weekdays: unknown weekday %q", name)
+		}
+	}
+	return weekdays, nil
+}
+
+// Patterns compiles Include and Exclude
+func (c *DiscoveryConfig) Patterns() (include, exclude []*regexp.Regexp, err error) {
+	compile := func(field string, patterns []string) ([]*regexp.Regexp, error) {
+		compiled := make([]*regexp.Regexp, 0, len(patterns))
+		for _, pattern := range patterns {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return nil, fmt.Errorf("discovery.%s: invalid pattern %q: %w", field, pattern, err)
+			}
+			compiled = append(compiled, re)
+		}
+		return compiled, nil
+	}
+
+	if include, err = compile("include", c.Include); err != nil {
+		return nil, nil, err
+	}
+	if exclude, err = compile("exclude", c.Exclude); err != nil {
+		return nil, nil, err
+	}
+	return include, exclude, nil
+}
+
 // GetDSN returns PostgreSQL connection string
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -6,6 +6,8 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/farhanasfar/airbnb-market-scraping-system/config"
 	"github.com/farhanasfar/airbnb-market-scraping-system/models"
@@ -20,9 +22,45 @@ func main() {
 	showStats := flag.Bool("show-stats", false, "Show all analytics statistics")
 	avgPrice := flag.Bool("avg-price", false, "Show average price")
 	maxPrice := flag.Bool("max-price", false, "Show maximum price and property details")
-	topRated := flag.Bool("top-rated", false, "Show top 5 highest rated properties")
+	topRated := flag.Bool("top-rated", false, "Show highest rated properties, weighted by review count")
+	minReviews := flag.Int("min-reviews", -1, "Minimum review count for --top-rated (-1 = config value)")
+	perLocation := flag.Bool("per-location", false, "Rank --top-rated separately for each location")
 	byLocation := flag.Bool("by-location", false, "Show listings grouped by location")
+	priceDistribution := flag.Bool("price-distribution", false, "Show price percentiles and histogram overall and per location")
+	marketSummary := flag.Bool("market-summary", false, "Show per-location market summary table")
+	sortBy := flag.String("sort", "count", "Market summary sort column: "+strings.Join(services.SummaryColumns(), ", "))
+	summaryBy := flag.String("summary-by", "location", "Group the market summary by \"location\" or \"segment\"")
+	ascending := flag.Bool("asc", false, "Sort the market summary in ascending order")
+	bestValue := flag.Bool("best-value", false, "Show the best value properties per location")
+	limit := flag.Int("limit", 5, "Number of properties for ranked reports (--top-rated, --best-value)")
+	dataQuality := flag.Bool("data-quality", false, "List price outliers and suspicious records with reasons")
+	includeFlagged := flag.Bool("include-flagged", false, "Keep listings flagged by the data quality checks in analytics")
+	priceTrends := flag.Bool("price-trends", false, "Show week-over-week and month-over-month price changes and the largest price moves")
+	trendDays := flag.Int("trend-days", 90, "Number of days of price history for --price-trends and --export-trends")
+	exportTrends := flag.String("export-trends", "", "Export the daily median price per location to this CSV file")
+	diffRuns := flag.String("diff", "", "Compare two scrape runs or dates: <run-id|YYYY-MM-DD>,<run-id|YYYY-MM-DD>")
+	jsonOutput := flag.Bool("json", false, "Print the --diff report as JSON")
+	listRuns := flag.Bool("runs", false, "List recent scrape runs (use --limit to show more)")
+	testAlerts := flag.Bool("test-alerts", false, "Send a test alert through the configured webhook and SMTP channels")
+	findDuplicates := flag.Bool("find-duplicates", false, "Detect probable duplicate listings, store the clusters and list them")
+	countDuplicatesOnce := flag.Bool("count-duplicates-once", false, "Count each probable duplicate cluster once in analytics")
+	fairPrice := flag.Bool("fair-price", false, "Train the fair-price model and list the most under- and over-priced listings")
+	scrapeCalendars := flag.Bool("scrape-calendars", false, "Scrape the availability calendar of every stored listing")
+	occupancy := flag.Bool("occupancy", false, "Estimate occupancy, ADR and RevPAR per location from stored calendars")
+	priceSweep := flag.String("price-sweep", "", "Quote listings for a grid of check-in dates and stay lengths: <location> or <listing-id|url>[,...]")
+	sweepReport := flag.Bool("sweep-report", false, "Show weekend premiums, seasonality and length-of-stay discounts from price quotes")
+	segments := flag.Bool("segments", false, "Cluster listings into market segments, store the labels and show sizes and centroids per location")
+	comps := flag.String("comps", "", "Benchmark a listing against its most comparable listings: <listing-id|url>")
 	exportCSV := flag.Bool("export-csv", false, "Export listings to CSV file")
+	resolveLocations := flag.Bool("resolve-locations", false, "Link listings to canonical locations using the gazetteer")
+	watchAdd := flag.String("watch-add", "", "Add listings to the watchlist: <listing-id|url>[,...]")
+	watchNote := flag.String("watch-note", "", "Note stored with the listings added by --watch-add")
+	watchRemove := flag.String("watch-remove", "", "Remove listings from the watchlist: <listing-id|url>[,...]")
+	watchlist := flag.Bool("watchlist", false, "List the watched listings")
+	refreshWatchlist := flag.Bool("refresh-watchlist", false, "Re-scrape only the watched listings and record snapshots for them")
+	discovered := flag.Bool("discovered", false, "List the locations and categories discovered on the homepage and whether they are crawled")
+	seedURLs := flag.String("seed", "", "Crawl the listings of saved search or wishlist URLs, separated by spaces")
+	crawlArea := flag.String("crawl-area", "", "Enumerate every listing in a bounding box: south,west,north,east")
 
 	flag.Parse()
 
@@ -34,6 +72,13 @@ func main() {
 		log.Fatal("Failed to load config:", err)
 	}
 
+	if *includeFlagged {
+		cfg.Analytics.IncludeFlagged = true
+	}
+	if *countDuplicatesOnce {
+		cfg.Analytics.CountDuplicatesOnce = true
+	}
+
 	// Connect to database
 	db, err := storage.NewDB(cfg.Database.GetDSN())
 	if err != nil {
@@ -42,8 +87,9 @@ func main() {
 	defer db.Close()
 
 	// Create services
-	analyticsService := services.NewAnalyticsService(db, logger)
+	analyticsService := services.NewAnalyticsService(db, logger, &cfg.Analytics)
 	csvService := services.NewCSVService(db, logger)
+	trendService := services.NewTrendService(db, logger)
 
 	// Handle analytics flags (no scraping needed)
 	if *showStats {
@@ -70,7 +116,7 @@ func main() {
 	}
 
 	if *topRated {
-		if err := analyticsService.PrintTopRated(); err != nil {
+		if err := analyticsService.PrintTopRated(*limit, *minReviews, *perLocation); err != nil {
 			log.Fatal("Failed to get top rated:", err)
 		}
 		return
@@ -83,6 +129,137 @@ func main() {
 		return
 	}
 
+	if *priceDistribution {
+		if err := analyticsService.PrintPriceDistribution(); err != nil {
+			log.Fatal("Failed to get price distribution:", err)
+		}
+		return
+	}
+
+	if *marketSummary {
+		if err := analyticsService.PrintMarketSummary(*summaryBy, *sortBy, !*ascending); err != nil {
+			log.Fatal("Failed to get market summary:", err)
+		}
+		return
+	}
+
+	if *bestValue {
+		if err := analyticsService.PrintBestValue(*limit); err != nil {
+			log.Fatal("Failed to get best value:", err)
+		}
+		return
+	}
+
+	if *dataQuality {
+		if err := analyticsService.PrintDataQuality(); err != nil {
+			log.Fatal("Failed to check data quality:", err)
+		}
+		return
+	}
+
+	if *priceTrends {
+		if err := trendService.PrintTrends(*trendDays, *limit); err != nil {
+			log.Fatal("Failed to get price trends:", err)
+		}
+		return
+	}
+
+	if *exportTrends != "" {
+		if err := trendService.ExportSeriesCSV(*exportTrends, *trendDays); err != nil {
+			log.Fatal("Failed to export price trends:", err)
+		}
+		return
+	}
+
+	if *diffRuns != "" {
+		sides := strings.Split(*diffRuns, ",")
+		if len(sides) != 2 {
+			log.Fatal("Invalid --diff: expected two runs or dates separated by a comma")
+		}
+		diffService := services.NewDiffService(db, logger)
+		from, to := strings.TrimSpace(sides[0]), strings.TrimSpace(sides[1])
+		if *jsonOutput {
+			data, err := diffService.DiffJSON(from, to)
+			if err != nil {
+				log.Fatal("Failed to compare runs:", err)
+			}
+			fmt.Println(string(data))
+		} else if err := diffService.PrintDiff(from, to); err != nil {
+			log.Fatal("Failed to compare runs:", err)
+		}
+		return
+	}
+
+	if *listRuns {
+		if err := services.NewDiffService(db, logger).PrintRuns(*limit); err != nil {
+			log.Fatal("Failed to list scrape runs:", err)
+		}
+		return
+	}
+
+	if *testAlerts {
+		if err := services.NewAlertService(db, logger, &cfg.Alerts).SendTest(); err != nil {
+			log.Fatal("Failed to send test alert:", err)
+		}
+		return
+	}
+
+	if *findDuplicates {
+		if err := services.NewDuplicateService(db, logger, &cfg.Duplicates).PrintDuplicates(); err != nil {
+			log.Fatal("Failed to find duplicates:", err)
+		}
+		return
+	}
+
+	if *fairPrice {
+		fairPriceService := services.NewFairPriceService(db, logger, &cfg.PriceModel, &cfg.Analytics)
+		if err := fairPriceService.PrintFairPrice(*limit); err != nil {
+			log.Fatal("Failed to run fair-price model:", err)
+		}
+		return
+	}
+
+	if *scrapeCalendars {
+		runCalendarScrape(cfg, db, logger)
+		return
+	}
+
+	if *occupancy {
+		occupancyService := services.NewOccupancyService(db, logger)
+		if err := occupancyService.PrintOccupancy(cfg.Scraper.CalendarDays, *limit); err != nil {
+			log.Fatal("Failed to estimate occupancy:", err)
+		}
+		return
+	}
+
+	if *priceSweep != "" {
+		runPriceSweep(cfg, db, logger, *priceSweep)
+		return
+	}
+
+	if *sweepReport {
+		if err := services.NewSweepService(db, logger, &cfg.PriceSweep).PrintSweepReport(); err != nil {
+			log.Fatal("Failed to get sweep report:", err)
+		}
+		return
+	}
+
+	if *segments {
+		segmentService := services.NewSegmentService(db, logger, &cfg.Segments, &cfg.Analytics)
+		if err := segmentService.PrintSegments(); err != nil {
+			log.Fatal("Failed to segment listings:", err)
+		}
+		return
+	}
+
+	if *comps != "" {
+		compsService := services.NewCompsService(db, logger, &cfg.Comps, &cfg.Analytics)
+		if err := compsService.PrintComps(*comps); err != nil {
+			log.Fatal("Failed to find comps:", err)
+		}
+		return
+	}
+
 	if *exportCSV {
 		if err := csvService.ExportToCSV(cfg.Output.CSVFile); err != nil {
 			log.Fatal("Failed to export CSV:", err)
@@ -90,6 +267,57 @@ func main() {
 		return
 	}
 
+	if *resolveLocations {
+		locationService := services.NewLocationService(db, logger, loadGazetteer(cfg, logger))
+		if _, err := locationService.ResolveListings(); err != nil {
+			log.Fatal("Failed to resolve locations:", err)
+		}
+		return
+	}
+
+	if *watchAdd != "" {
+		if err := services.NewWatchlistService(db, logger).Add(strings.Split(*watchAdd, ","), *watchNote); err != nil {
+			log.Fatal("Failed to add to watchlist:", err)
+		}
+		return
+	}
+
+	if *watchRemove != "" {
+		if err := services.NewWatchlistService(db, logger).Remove(strings.Split(*watchRemove, ",")); err != nil {
+			log.Fatal("Failed to remove from watchlist:", err)
+		}
+		return
+	}
+
+	if *watchlist {
+		if err := services.NewWatchlistService(db, logger).PrintWatchlist(); err != nil {
+			log.Fatal("Failed to list watchlist:", err)
+		}
+		return
+	}
+
+	if *refreshWatchlist {
+		runWatchlistRefresh(cfg, db, logger)
+		return
+	}
+
+	if *crawlArea != "" {
+		runAreaCrawl(cfg, db, logger, *crawlArea)
+		return
+	}
+
+	if *discovered {
+		if err := services.NewDiscoveryService(db, logger, &cfg.Discovery).PrintDiscovered(); err != nil {
+			log.Fatal("Failed to list discovered locations:", err)
+		}
+		return
+	}
+
+	if *seedURLs != "" {
+		runSeedCrawl(cfg, db, logger, strings.Fields(*seedURLs))
+		return
+	}
+
 	// No flags = run scraping (default behavior)
 	runScraping(cfg, db, logger)
 }
@@ -97,28 +325,58 @@ func main() {
 func runScraping(cfg *config.Config, db *storage.DB, logger *utils.Logger) {
 	logger.Info("Starting Airbnb Multi-Location Scraper...")
 
-	// Create services
-	listingService := services.NewListingService(db, logger)
-	csvService := services.NewCSVService(db, logger)
-	analyticsService := services.NewAnalyticsService(db, logger)
 	scraper := airbnb.NewScraper(&cfg.Scraper, logger)
+	locationService := services.NewLocationService(db, logger, loadGazetteer(cfg, logger))
 	ctx := context.Background()
 
+	run, err := db.CreateScrapeRun(models.RunKindFull)
+	if err != nil {
+		log.Fatal("Failed to start scrape run:", err)
+	}
+	defer finishRun(db, logger, run)
+
 	// Step 1: Scrape homepage to get location URLs
 	logger.Info("\n=== STEP 1: EXTRACTING LOCATIONS FROM HOMEPAGE ===")
-	locations, err := scraper.ScrapeHomepageLocations(ctx)
+	cards, err := scraper.ScrapeHomepageLocations(ctx)
 	if err != nil {
+		run.Status = "failed"
+		finishRun(db, logger, run)
 		log.Fatal("Failed to scrape homepage:", err)
 	}
 
-	if len(locations) == 0 {
+	if len(cards) == 0 {
 		logger.Warning("No locations found on homepage")
-		return
 	}
 
-	logger.Info("Found %d locations:", len(locations))
+	found := make([]models.DiscoveredLocation, 0, len(cards))
+	for _, card := range cards {
+		found = append(found, models.DiscoveredLocation{
+			Kind: card.Kind, Group: card.Group, Region: card.Region, Name: card.Name, URL: card.URL,
+		})
+	}
+	discoveryService := services.NewDiscoveryService(db, logger, &cfg.Discovery)
+	locations, err := discoveryService.Record(found)
+	if err != nil {
+		logger.Error("Failed to record discovered locations: %v", err)
+		locations = discoveryService.Select(found)
+	}
+
+	logger.Info("Crawling %d of %d discovered locations:", len(locations), len(found))
 	for i, loc := range locations {
-		logger.Info("  %d. %s", i+1, loc.Name)
+		place := loc.SearchPlace()
+		if place == "" {
+			logger.Info("  %d. %s (%s)", i+1, loc.Name, loc.Kind)
+			continue
+		}
+		canonical, err := locationService.Resolve(place, "")
+		if err != nil {
+			logger.Warning("Failed to resolve %s: %v", place, err)
+		}
+		if canonical != nil {
+			logger.Info("  %d. %s (%s)", i+1, loc.Path(), canonical.DisplayName())
+		} else {
+			logger.Info("  %d. %s", i+1, loc.Path())
+		}
 	}
 
 	// Step 2: Scrape properties from each location
@@ -126,12 +384,20 @@ func runScraping(cfg *config.Config, db *storage.DB, logger *utils.Logger) {
 
 	allRawListings := []models.RawListing{}
 	totalProperties := 0
+	locationCounts := make(map[string]int, len(locations))
 
 	for i, location := range locations {
 		logger.Info("\n[%d/%d] Scraping: %s", i+1, len(locations), location.Name)
 
-		// Scrape this location (2 pages × 5 properties = 10 per location)
-		rawListings, err := scraper.ScrapeListings(ctx, location.URL)
+		// Scrape this location (MaxPages × PropertiesPerPage, or every page in exhaust mode)
+		rawListings, err := scraper.ScrapeListings(ctx, location.URL, cfg.Scraper.QuotaFor(location.Name))
+
+		// Two searches of the same kind and name (different URLs) are counted apart
+		countKey := location.CountKey()
+		if _, taken := locationCounts[countKey]; taken {
+			countKey += " " + location.URL
+		}
+		locationCounts[countKey] = len(rawListings)
 		if err != nil {
 			logger.Error("Failed to scrape %s: %v", location.Name, err)
 			continue
@@ -142,6 +408,11 @@ func runScraping(cfg *config.Config, db *storage.DB, logger *utils.Logger) {
 			continue
 		}
 
+		// Category searches span every place, so their listings get no search location
+		for j := range rawListings {
+			rawListings[j].SearchLocation = location.SearchPlace()
+		}
+
 		logger.Success("Got %d properties from %s", len(rawListings), location.Name)
 		allRawListings = append(allRawListings, rawListings...)
 		totalProperties += len(rawListings)
@@ -150,11 +421,393 @@ func runScraping(cfg *config.Config, db *storage.DB, logger *utils.Logger) {
 	logger.Success("\n=== SCRAPED %d TOTAL PROPERTIES FROM %d LOCATIONS ===",
 		totalProperties, len(locations))
 
+	savedCount := 0
 	if totalProperties == 0 {
-		logger.Warning("No properties scraped, exiting")
+		logger.Warning("No properties scraped")
+	} else {
+		run.ListingsFound = totalProperties
+		savedCount = processListings(ctx, cfg, db, logger, scraper, locationService, run, allRawListings)
+		run.ListingsSaved = savedCount
+	}
+
+	// Health checks
+	healthy := checkHealth(cfg, db, logger, scraper, run, allRawListings, locationCounts)
+	run.Status = "completed"
+	if !healthy && cfg.Health.FailOnBreach {
+		run.Status = "failed"
+	}
+	finishRun(db, logger, run)
+
+	// Alerts
+	logger.Info("\n=== ALERTS ===")
+	sendAlerts(cfg, db, logger, run, locationCounts)
+
+	if run.Status == "failed" {
+		log.Fatal("Scrape run failed health checks")
+	}
+
+	// Final summary
+	logger.Success("\n=== SCRAPING COMPLETE ===")
+	logger.Info("Locations scraped: %d", len(locations))
+	logger.Info("Total properties found: %d", totalProperties)
+	logger.Info("Successfully saved: %d", savedCount)
+	logger.Info("CSV file: %s", cfg.Output.CSVFile)
+	logger.Info("\n💡 Tip: Run with --show-stats to see analytics anytime!")
+	logger.Info("   Other flags: --avg-price, --max-price, --top-rated, --by-location, --price-distribution, --market-summary, --best-value, --fair-price, --comps, --segments, --watchlist, --occupancy, --sweep-report, --data-quality, --find-duplicates, --price-trends, --runs, --discovered, --diff, --export-csv")
+}
+
+// runAreaCrawl enumerates every listing inside a bounding box using map tiles
+func runAreaCrawl(cfg *config.Config, db *storage.DB, logger *utils.Logger, rawBox string) {
+	box, err := airbnb.ParseBoundingBox(rawBox)
+	if err != nil {
+		log.Fatal("Invalid --crawl-area: ", err)
+	}
+
+	logger.Info("Starting Airbnb map-tiling crawl...")
+	scraper := airbnb.NewScraper(&cfg.Scraper, logger)
+	ctx := context.Background()
+
+	run, err := db.CreateScrapeRun(models.RunKindArea)
+	if err != nil {
+		log.Fatal("Failed to start scrape run:", err)
+	}
+	defer finishRun(db, logger, run)
+
+	result, err := scraper.CrawlArea(ctx, cfg.Scraper.TileSearchURL, box)
+	if err != nil {
+		run.Status = "failed"
+		finishRun(db, logger, run)
+		log.Fatal("Failed to crawl area:", err)
+	}
+
+	savedCount := 0
+	if len(result.Listings) == 0 {
+		logger.Warning("No properties found in area")
+	} else {
+		locationService := services.NewLocationService(db, logger, loadGazetteer(cfg, logger))
+		run.ListingsFound = len(result.Listings)
+		savedCount = processListings(ctx, cfg, db, logger, scraper, locationService, run, result.Listings)
+		run.ListingsSaved = savedCount
+	}
+
+	healthy := checkHealth(cfg, db, logger, scraper, run, result.Listings, nil)
+	run.Status = "completed"
+	if !healthy && cfg.Health.FailOnBreach {
+		run.Status = "failed"
+	}
+	finishRun(db, logger, run)
+
+	sendAlerts(cfg, db, logger, run, nil)
+
+	if run.Status == "failed" {
+		log.Fatal("Area crawl failed health checks")
+	}
+
+	logger.Success("\n=== AREA CRAWL COMPLETE ===")
+	logger.Info("Tiles searched: %d (split: %d)", result.TilesSearched, result.TilesSplit)
+	if result.CappedTiles > 0 {
+		logger.Warning("%d tiles were still capped at max depth; supply is undercounted", result.CappedTiles)
+	}
+	logger.Info("Unique listings in area: %d", len(result.Listings))
+	logger.Info("Successfully saved: %d", savedCount)
+}
+
+// runSeedCrawl crawls the listings behind saved search and wishlist URLs instead of the homepage locations
+func runSeedCrawl(cfg *config.Config, db *storage.DB, logger *utils.Logger, seeds []string) {
+	for _, seed := range seeds {
+		if _, err := airbnb.SeedKind(seed); err != nil {
+			log.Fatal("Invalid --seed: ", err)
+		}
+	}
+
+	logger.Info("Starting Airbnb seed crawl from %d URLs...", len(seeds))
+	scraper := airbnb.NewScraper(&cfg.Scraper, logger)
+	ctx := context.Background()
+
+	run, err := db.CreateScrapeRun(models.RunKindSeed)
+	if err != nil {
+		log.Fatal("Failed to start scrape run:", err)
+	}
+	defer finishRun(db, logger, run)
+
+	allRawListings := []models.RawListing{}
+	seen := make(map[string]bool)
+	for i, seed := range seeds {
+		logger.Info("\n[%d/%d] Expanding: %s", i+1, len(seeds), seed)
+		rawListings, err := scraper.ExpandSeed(ctx, seed)
+		if err != nil {
+			logger.Error("Failed to expand %s: %v", seed, err)
+			continue
+		}
+
+		added, priceless := 0, 0
+		for _, listing := range rawListings {
+			id := utils.ExtractListingID(listing.URL)
+			if id == "" || seen[id] {
+				continue
+			}
+			// Wishlists without dates show no price; saving would overwrite the last known one with zero
+			if utils.NormalizePrice(listing.Price) == 0 {
+				priceless++
+				continue
+			}
+			seen[id] = true
+			allRawListings = append(allRawListings, listing)
+			added++
+		}
+		if priceless > 0 {
+			logger.Warning("Skipped %d properties without a price; give the wishlist dates to include them", priceless)
+		}
+		logger.Success("Got %d new properties from seed %d", added, i+1)
+	}
+
+	savedCount := 0
+	if len(allRawListings) == 0 {
+		logger.Warning("No properties found behind the seeds")
+	} else {
+		locationService := services.NewLocationService(db, logger, loadGazetteer(cfg, logger))
+		run.ListingsFound = len(allRawListings)
+		savedCount = processListings(ctx, cfg, db, logger, scraper, locationService, run, allRawListings)
+		run.ListingsSaved = savedCount
+	}
+
+	healthy := checkHealth(cfg, db, logger, scraper, run, allRawListings, nil)
+	run.Status = "completed"
+	if !healthy && cfg.Health.FailOnBreach {
+		run.Status = "failed"
+	}
+	finishRun(db, logger, run)
+
+	sendAlerts(cfg, db, logger, run, nil)
+
+	if run.Status == "failed" {
+		log.Fatal("Seed crawl failed health checks")
+	}
+
+	logger.Success("\n=== SEED CRAWL COMPLETE ===")
+	logger.Info("Seeds: %d", len(seeds))
+	logger.Info("Unique listings: %d", len(allRawListings))
+	logger.Info("Successfully saved: %d", savedCount)
+}
+
+// runWatchlistRefresh re-scrapes the detail and price pages of the watched listings only
+// and records a snapshot of each, without homepage discovery or search pagination
+func runWatchlistRefresh(cfg *config.Config, db *storage.DB, logger *utils.Logger) {
+	watchlistService := services.NewWatchlistService(db, logger)
+	watched, err := watchlistService.GetWatchlist()
+	if err != nil {
+		log.Fatal("Failed to get watchlist:", err)
+	}
+	if len(watched) == 0 {
+		logger.Warning("Watchlist is empty; add listings with --watch-add")
 		return
 	}
 
+	logger.Info("Refreshing %d watched listings...", len(watched))
+	scraper := airbnb.NewScraper(&cfg.Scraper, logger)
+	ctx := context.Background()
+
+	run, err := db.CreateScrapeRun(models.RunKindWatch)
+	if err != nil {
+		log.Fatal("Failed to start scrape run:", err)
+	}
+	defer finishRun(db, logger, run)
+
+	urls := make([]string, 0, len(watched))
+	for _, w := range watched {
+		urls = append(urls, w.URL)
+	}
+
+	now := time.Now()
+	checkIn := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, cfg.Watchlist.CheckInDays)
+	logger.Info("Reference stay: %d nights from %s for %d adults", cfg.Watchlist.Nights, checkIn.Format("2006-01-02"), cfg.Watchlist.Adults)
+	results := scraper.ScrapeWatchlistWithWorkers(ctx, urls, checkIn, cfg.Watchlist.Nights, cfg.Watchlist.Adults)
+
+	run.DetailsAttempted = len(urls)
+	rawListings := []models.RawListing{}
+	refreshed := []string{}
+	for _, url := range urls {
+		result := results[url]
+		if result == nil || result.Error != nil {
+			continue
+		}
+		run.DetailsSucceeded++
+
+		// Saving without a price would overwrite the last known one with zero
+		if result.Listing.Price == "" {
+			logger.Warning("No nightly rate for %s on the reference dates; keeping the last snapshot", url)
+			continue
+		}
+		rawListings = append(rawListings, result.Listing)
+		refreshed = append(refreshed, utils.ExtractListingID(url))
+	}
+	run.ListingsFound = len(rawListings)
+
+	if err := watchlistService.CompleteRawListings(rawListings); err != nil {
+		logger.Error("Failed to complete watched listings: %v", err)
+	}
+
+	listingService := services.NewListingService(db, logger)
+	savedCount, err := listingService.NormalizeAndSave(run.ID, rawListings)
+	if err != nil {
+		logger.Error("Failed to save watched listings: %v", err)
+	}
+	run.ListingsSaved = savedCount
+
+	// Listings watched before they were ever crawled are new to the table
+	locationService := services.NewLocationService(db, logger, loadGazetteer(cfg, logger))
+	if _, err := locationService.ResolveListings(); err != nil {
+		logger.Error("Failed to resolve locations: %v", err)
+	}
+
+	if err := db.MarkWatchlistRefreshed(refreshed); err != nil {
+		logger.Error("Failed to record watchlist refresh: %v", err)
+	}
+
+	healthy := checkHealth(cfg, db, logger, scraper, run, rawListings, nil)
+	run.Status = "completed"
+	if !healthy && cfg.Health.FailOnBreach {
+		run.Status = "failed"
+	}
+	finishRun(db, logger, run)
+
+	sendAlerts(cfg, db, logger, run, nil)
+
+	if run.Status == "failed" {
+		log.Fatal("Watchlist refresh failed health checks")
+	}
+
+	logger.Success("\n=== WATCHLIST REFRESH COMPLETE ===")
+	logger.Info("Watched listings: %d", len(watched))
+	logger.Info("Refreshed: %d", savedCount)
+	if failed := len(watched) - run.DetailsSucceeded; failed > 0 {
+		logger.Warning("Failed: %d", failed)
+	}
+}
+
+// runCalendarScrape scrapes the availability calendar of every stored listing
+func runCalendarScrape(cfg *config.Config, db *storage.DB, logger *utils.Logger) {
+	logger.Info("Starting calendar scraping...")
+	scraper := airbnb.NewScraper(&cfg.Scraper, logger)
+	if saved := scrapeCalendars(context.Background(), cfg, db, logger, scraper, nil); saved == 0 {
+		log.Fatal("No calendars saved")
+	}
+	logger.Info("\n💡 Tip: Run with --occupancy to estimate occupancy and revenue")
+}
+
+// scrapeCalendars scrapes and stores the calendars of the stored listings matching the given URLs by room ID (nil = all)
+// Returns the number of listings whose calendar was saved
+func scrapeCalendars(ctx context.Context, cfg *config.Config, db *storage.DB, logger *utils.Logger,
+	scraper *airbnb.Scraper, urls []string) int {
+	listings, err := db.GetAllListings()
+	if err != nil {
+		logger.Error("Failed to get listings: %v", err)
+		return 0
+	}
+
+	if urls != nil {
+		wanted := make(map[string]bool, len(urls))
+		for _, url := range urls {
+			wanted[utils.ExtractListingID(url)] = true
+		}
+		selected := listings[:0]
+		for _, listing := range listings {
+			if wanted[utils.ExtractListingID(listing.URL)] {
+				selected = append(selected, listing)
+			}
+		}
+		listings = selected
+	}
+
+	listingURLs := make([]string, 0, len(listings))
+	for _, listing := range listings {
+		listingURLs = append(listingURLs, listing.URL)
+	}
+
+	logger.Info("Scraping calendars for %d properties (%d nights)...", len(listingURLs), cfg.Scraper.CalendarDays)
+	results := scraper.ScrapeCalendarsWithWorkers(ctx, listingURLs, cfg.Scraper.CalendarDays)
+
+	calendars := make(map[string][]models.CalendarDay, len(results))
+	for url, result := range results {
+		if result.Error == nil {
+			calendars[url] = result.Days
+		}
+	}
+
+	return services.NewOccupancyService(db, logger).SaveCalendars(listings, calendars)
+}
+
+// runPriceSweep quotes the target listings for every configured check-in date and stay length
+func runPriceSweep(cfg *config.Config, db *storage.DB, logger *utils.Logger, target string) {
+	sweepService := services.NewSweepService(db, logger, &cfg.PriceSweep)
+
+	listings, err := sweepService.SelectListings(target)
+	if err != nil {
+		log.Fatal("Failed to select listings:", err)
+	}
+	if len(listings) == 0 {
+		log.Fatal("No stored listings match ", target)
+	}
+
+	checkIns := sweepService.CheckInDates(time.Now())
+	requests := []airbnb.QuoteRequest{}
+	listingIDs := make(map[string]int, len(listings))
+	for _, listing := range listings {
+		listingIDs[listing.URL] = listing.ID
+		for _, checkIn := range checkIns {
+			for _, nights := range cfg.PriceSweep.StayLengths {
+				requests = append(requests, airbnb.QuoteRequest{URL: listing.URL, CheckIn: checkIn, Nights: nights})
+			}
+		}
+	}
+
+	logger.Info("Starting date sweep: %d listings × %d check-in dates × %d stay lengths = %d quotes",
+		len(listings), len(checkIns), len(cfg.PriceSweep.StayLengths), len(requests))
+
+	scraper := airbnb.NewScraper(&cfg.Scraper, logger)
+	results := scraper.ScrapeQuotesWithWorkers(context.Background(), requests, cfg.PriceSweep.Adults)
+
+	quotes := []models.PriceQuote{}
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+		quote := models.PriceQuote{
+			ListingID:  listingIDs[result.URL],
+			CheckIn:    result.CheckIn,
+			Nights:     result.Nights,
+			Adults:     cfg.PriceSweep.Adults,
+			Available:  result.Available,
+			TotalPrice: result.TotalPrice,
+			PriceBasis: result.PriceBasis,
+		}
+		if result.Available {
+			quote.NightlyPrice = result.TotalPrice / float64(result.Nights)
+		}
+		quotes = append(quotes, quote)
+	}
+
+	if len(quotes) == 0 {
+		log.Fatal("No price quotes scraped")
+	}
+	if err := sweepService.SaveQuotes(quotes); err != nil {
+		log.Fatal("Failed to save price quotes:", err)
+	}
+
+	if err := sweepService.PrintSweepReport(); err != nil {
+		logger.Error("Failed to get sweep report: %v", err)
+	}
+}
+
+// processListings scrapes detail pages for raw listings, saves them, exports CSV and prints analytics
+// Returns the number of listings saved
+func processListings(ctx context.Context, cfg *config.Config, db *storage.DB, logger *utils.Logger,
+	scraper *airbnb.Scraper, locationService *services.LocationService, run *models.ScrapeRun,
+	allRawListings []models.RawListing) int {
+	listingService := services.NewListingService(db, logger)
+	csvService := services.NewCSVService(db, logger)
+	analyticsService := services.NewAnalyticsService(db, logger, &cfg.Analytics)
+
 	// Print preview if JSON console enabled
 	if cfg.Output.JSONConsole {
 		logger.Info("\n=== PREVIEW (first 2 listings) ===")
@@ -179,23 +832,57 @@ func runScraping(cfg *config.Config, db *storage.DB, logger *utils.Logger) {
 	logger.Info("Scraping details for %d properties...", len(urls))
 	detailResults := scraper.ScrapeDetailsWithWorkers(ctx, urls)
 
+	run.DetailsAttempted = len(detailResults)
+	for _, detail := range detailResults {
+		if detail.Error == nil {
+			run.DetailsSucceeded++
+		}
+	}
+
 	// Merge detail data
 	for i := range allRawListings {
 		normalizedURL := utils.NormalizeURL(allRawListings[i].URL)
 		if detail, ok := detailResults[normalizedURL]; ok && detail.Error == nil {
 			allRawListings[i].Bedrooms = detail.Bedrooms
+			allRawListings[i].Beds = detail.Beds
 			allRawListings[i].Bathrooms = detail.Bathrooms
+			allRawListings[i].HalfBaths = detail.HalfBaths
+			allRawListings[i].BathroomType = detail.BathroomType
+			allRawListings[i].Studio = detail.Studio
 			allRawListings[i].Guests = detail.Guests
+			allRawListings[i].Confidence = detail.Confidence
+			allRawListings[i].Latitude = detail.Latitude
+			allRawListings[i].Longitude = detail.Longitude
+			allRawListings[i].Amenities = detail.Amenities
 		}
 	}
 
 	// Step 4: Save to database
 	logger.Info("\n=== STEP 4: SAVING TO DATABASE ===")
-	savedCount, err := listingService.NormalizeAndSave(allRawListings)
+	savedCount, err := listingService.NormalizeAndSave(run.ID, allRawListings)
 	if err != nil {
 		logger.Error("Failed to save listings: %v", err)
 	}
 
+	if _, err := locationService.ResolveListings(); err != nil {
+		logger.Error("Failed to resolve locations: %v", err)
+	}
+
+	duplicateService := services.NewDuplicateService(db, logger, &cfg.Duplicates)
+	if _, err := duplicateService.FindDuplicates(); err != nil {
+		logger.Error("Failed to detect duplicates: %v", err)
+	}
+
+	segmentService := services.NewSegmentService(db, logger, &cfg.Segments, &cfg.Analytics)
+	if _, _, err := segmentService.FindSegments(); err != nil {
+		logger.Error("Failed to segment listings: %v", err)
+	}
+
+	if cfg.Scraper.ScrapeCalendar {
+		logger.Info("\n=== SCRAPING CALENDARS ===")
+		scrapeCalendars(ctx, cfg, db, logger, scraper, urls)
+	}
+
 	// Step 5: Export to CSV
 	logger.Info("\n=== STEP 5: EXPORTING TO CSV ===")
 	if err := csvService.ExportToCSV(cfg.Output.CSVFile); err != nil {
@@ -211,12 +898,69 @@ func runScraping(cfg *config.Config, db *storage.DB, logger *utils.Logger) {
 		analyticsService.PrintAnalytics(analytics)
 	}
 
-	// Final summary
-	logger.Success("\n=== SCRAPING COMPLETE ===")
-	logger.Info("Locations scraped: %d", len(locations))
-	logger.Info("Total properties found: %d", totalProperties)
-	logger.Info("Successfully saved: %d", savedCount)
-	logger.Info("CSV file: %s", cfg.Output.CSVFile)
-	logger.Info("\n💡 Tip: Run with --show-stats to see analytics anytime!")
-	logger.Info("   Other flags: --avg-price, --max-price, --top-rated, --by-location, --export-csv")
+	return savedCount
+}
+
+// finishRun stores the outcome of a scrape run once
+// A run still marked running when finishRun is called (e.g. no listings found) is completed as empty
+func finishRun(db *storage.DB, logger *utils.Logger, run *models.ScrapeRun) {
+	if run.FinishedAt != nil {
+		return
+	}
+	if run.Status == "running" {
+		run.Status = "completed"
+	}
+	if err := db.FinishScrapeRun(run); err != nil {
+		logger.Error("Failed to record scrape run: %v", err)
+	}
+}
+
+// checkHealth records the health metrics of a run and reports whether it passed every threshold
+// rawListings must already contain the detail page data; locationCounts is nil for area crawls
+func checkHealth(cfg *config.Config, db *storage.DB, logger *utils.Logger, scraper *airbnb.Scraper,
+	run *models.ScrapeRun, rawListings []models.RawListing, locationCounts map[string]int) bool {
+	logger.Info("\n=== SCRAPE HEALTH ===")
+
+	stats := scraper.Stats()
+	run.PagesScraped = stats.Pages
+	run.CardsFound = stats.Cards
+
+	healthService := services.NewHealthService(db, logger, &cfg.Health)
+	health, err := healthService.Check(run, services.EmptyFieldShares(rawListings), locationCounts)
+	if err != nil {
+		logger.Error("Failed to check scrape health: %v", err)
+		return true
+	}
+	healthService.PrintHealth(health)
+
+	if !health.Healthy() && cfg.Health.AlertOnBreach {
+		services.NewAlertService(db, logger, &cfg.Alerts).AlertHealth(run, health.Issues)
+	}
+
+	return health.Healthy()
+}
+
+// sendAlerts evaluates the configured alert rules for a finished run and delivers new alerts
+func sendAlerts(cfg *config.Config, db *storage.DB, logger *utils.Logger, run *models.ScrapeRun, locationCounts map[string]int) {
+	alertService := services.NewAlertService(db, logger, &cfg.Alerts)
+	if err := alertService.ProcessRun(run, locationCounts); err != nil {
+		logger.Error("Failed to evaluate alerts: %v", err)
+	}
+}
+
+// loadGazetteer loads the configured GeoNames dump
+// Returns nil (location resolution disabled) if it is not configured or cannot be read
+func loadGazetteer(cfg *config.Config, logger *utils.Logger) *utils.Gazetteer {
+	if cfg.Gazetteer.CitiesFile == "" {
+		return nil
+	}
+
+	gazetteer, err := utils.LoadGazetteer(cfg.Gazetteer.CitiesFile, cfg.Gazetteer.RegionsFile, cfg.Gazetteer.CountriesFile)
+	if err != nil {
+		logger.Warning("Gazetteer unavailable, locations will not be resolved: %v", err)
+		return nil
+	}
+
+	logger.Info("Loaded gazetteer with %d place names", gazetteer.Size())
+	return gazetteer
 }
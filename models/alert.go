@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// An alert raised by a rule; also the JSON payload posted to the alert webhook
+type Alert struct {
+	Rule          string    `json:"rule"`
+	Type          string    `json:"type"`
+	Key           string    `json:"key"` // identifies the event for deduplication within a rule
+	RunID         int       `json:"run_id,omitempty"`
+	Subject       string    `json:"subject"`
+	Message       string    `json:"message"`
+	Location      string    `json:"location,omitempty"`
+	Title         string    `json:"title,omitempty"`
+	URL           string    `json:"url,omitempty"`
+	Price         float64   `json:"price,omitempty"`
+	PreviousPrice float64   `json:"previous_price,omitempty"`
+	Rating        float64   `json:"rating,omitempty"`
+	TriggeredAt   time.Time `json:"triggered_at"`
+}
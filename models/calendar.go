@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// One night of a listing's availability calendar
+type CalendarDay struct {
+	ListingID int       `json:"listing_id" db:"listing_id"`
+	Date      time.Time `json:"date" db:"date"`
+	Available bool      `json:"available" db:"available"`
+	Price     float64   `json:"price" db:"price"` // 0 when the calendar has no price for the night
+	MinNights int       `json:"min_nights" db:"min_nights"`
+	ScrapedAt time.Time `json:"scraped_at" db:"scraped_at"`
+}
+
+// Calendar totals of one listing over a window of upcoming nights
+type CalendarSummary struct {
+	ListingID      int
+	Nights         int     // nights in the window with calendar data
+	BookedNights   int     // unavailable nights, assumed booked
+	BookedPriceAvg float64 // mean price of unavailable nights, 0 if none are priced
+	PriceAvg       float64 // mean price of all priced nights
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// A search discovered on the Airbnb homepage
+type DiscoveredLocation struct {
+	ID          int       `json:"id" db:"id"`
+	Kind        string    `json:"kind" db:"kind"`        // location, category or inspiration
+	Group       string    `json:"group" db:"group_name"` // inspiration tab ("Popular", "Beach"), empty otherwise
+	Region      string    `json:"region" db:"region"`    // second line of an inspiration card
+	Name        string    `json:"name" db:"name"`
+	URL         string    `json:"url" db:"url"`
+	FirstSeenAt time.Time `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// Path returns the position of the search in the homepage hierarchy: "Beach > Florida > Miami"
+func (d *DiscoveredLocation) Path() string {
+	parts := []string{}
+	for _, part := range []string{d.Group, d.Region, d.Name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+// SearchPlace returns the place the search covers, stored as the search location of its listings
+// Categories span every place and have none; inspiration destinations include their region: "Canmore, Alberta"
+func (d *DiscoveredLocation) SearchPlace() string {
+	switch d.Kind {
+	case "category":
+		return ""
+	case "inspiration":
+		if d.Region != "" {
+			return d.Name + ", " + d.Region
+		}
+	}
+	return d.Name
+}
+
+// CountKey names the search in per-location listing counts
+// Homepage locations keep their name so earlier baselines still apply; other kinds are prefixed
+// with their kind so a category and a destination of the same name never share a count
+func (d *DiscoveredLocation) CountKey() string {
+	if d.Kind == "location" || d.Kind == "" {
+		return d.Name
+	}
+	return d.Kind + ": " + d.Path()
+}
@@ -4,27 +4,61 @@ import "time"
 
 // Airbnb property listing
 type Listing struct {
-	ID        int       `json:"id" db:"id"`
-	Title     string    `json:"title" db:"title"`
-	Price     float64   `json:"price" db:"price"`
-	Location  string    `json:"location" db:"location"`
-	Rating    float64   `json:"rating" db:"rating"`
-	URL       string    `json:"url" db:"url"`
-	Bedrooms  int       `json:"bedrooms" db:"bedrooms"`
-	Bathrooms int       `json:"bathrooms" db:"bathrooms"`
-	Guests    int       `json:"guests" db:"guests"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID                int              `json:"id" db:"id"`
+	ListingID         string           `json:"listing_id" db:"listing_id"` // Airbnb room ID, the natural key
+	Title             string           `json:"title" db:"title"`
+	Price             float64          `json:"price" db:"price"`
+	Location          string           `json:"location" db:"location"`
+	PropertyType      string           `json:"property_type" db:"property_type"`     // "Loft in Richmond" -> Loft
+	City              string           `json:"city" db:"city"`                       // "Loft in Richmond" -> Richmond
+	SearchLocation    string           `json:"search_location" db:"search_location"` // homepage location the listing was found under
+	LocationID        int              `json:"location_id" db:"location_id"`         // 0 when unresolved
+	CanonicalLocation string           `json:"canonical_location" db:"-"`            // "Melbourne, Victoria, Australia"
+	Rating            float64          `json:"rating" db:"rating"`
+	ReviewCount       int              `json:"review_count" db:"review_count"`
+	URL               string           `json:"url" db:"url"`
+	Bedrooms          int              `json:"bedrooms" db:"bedrooms"` // 0 for studios
+	Beds              int              `json:"beds" db:"beds"`
+	Bathrooms         int              `json:"bathrooms" db:"bathrooms"`         // full baths
+	HalfBaths         int              `json:"half_baths" db:"half_baths"`       // baths without a shower or tub
+	BathroomType      string           `json:"bathroom_type" db:"bathroom_type"` // "private", "shared" or empty when unknown
+	Studio            bool             `json:"studio" db:"studio"`
+	Guests            int              `json:"guests" db:"guests"`
+	Confidence        DetailConfidence `json:"confidence" db:"-"`        // how the capacity fields were read
+	Latitude          float64          `json:"latitude" db:"latitude"`   // 0 when unknown
+	Longitude         float64          `json:"longitude" db:"longitude"` // 0 when unknown
+	Amenities         int              `json:"amenities" db:"amenities"` // number of amenities, 0 when unknown
+	ClusterID         int              `json:"cluster_id" db:"-"`        // duplicate cluster, 0 when not a probable duplicate
+	Segment           string           `json:"segment" db:"-"`           // market segment label, empty when not segmented
+	CreatedAt         time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at" db:"updated_at"`
 }
 
 // structure before normalization
 type RawListing struct {
-	Title     string
-	Price     string
-	Location  string
-	Rating    string
-	URL       string
-	Bedrooms  int
-	Bathrooms int
-	Guests    int
+	Title          string
+	Price          string
+	Location       string
+	SearchLocation string
+	Rating         string
+	URL            string
+	Bedrooms       int
+	Beds           int
+	Bathrooms      int
+	HalfBaths      int
+	BathroomType   string
+	Studio         bool
+	Guests         int
+	Confidence     DetailConfidence
+	Latitude       float64
+	Longitude      float64
+	Amenities      int
+}
+
+// DetailConfidence rates how each capacity field was read from the detail page, from 0 (not read) to 1
+type DetailConfidence struct {
+	Guests    float64 `json:"guests" db:"guests_confidence"`
+	Bedrooms  float64 `json:"bedrooms" db:"bedrooms_confidence"` // also covers the studio flag
+	Beds      float64 `json:"beds" db:"beds_confidence"`
+	Bathrooms float64 `json:"bathrooms" db:"bathrooms_confidence"` // also covers half baths and bathroom type
 }
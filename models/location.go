@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Canonical location resolved from the gazetteer
+type Location struct {
+	ID          int       `json:"id" db:"id"`
+	GeonameID   int64     `json:"geoname_id" db:"geoname_id"`
+	City        string    `json:"city" db:"city"`
+	Region      string    `json:"region" db:"region"`
+	Country     string    `json:"country" db:"country"`
+	CountryCode string    `json:"country_code" db:"country_code"`
+	Latitude    float64   `json:"latitude" db:"latitude"`
+	Longitude   float64   `json:"longitude" db:"longitude"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// DisplayName formats the location as "City, Region, Country"
+func (l *Location) DisplayName() string {
+	name := l.City
+	if l.Region != "" && l.Region != l.City {
+		name += ", " + l.Region
+	}
+	if l.Country != "" {
+		name += ", " + l.Country
+	}
+	return name
+}
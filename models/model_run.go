@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Evaluation of one training run of the fair-price model
+type ModelRun struct {
+	ID          int       `json:"id" db:"id"`
+	Algorithm   string    `json:"algorithm" db:"algorithm"`
+	Samples     int       `json:"samples" db:"samples"`           // listings used for training
+	TestSamples int       `json:"test_samples" db:"test_samples"` // held-out listings the metrics are computed on
+	Features    int       `json:"features" db:"features"`
+	Lambda      float64   `json:"lambda" db:"lambda"`
+	MAE         float64   `json:"mae" db:"mae"` // mean absolute error in price units
+	R2          float64   `json:"r2" db:"r2"`
+	TrainedAt   time.Time `json:"trained_at" db:"trained_at"`
+}
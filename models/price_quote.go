@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Price bases of a quote total
+const (
+	PriceBasisTotal   = "total"   // the stay total shown by the booking panel, including fees
+	PriceBasisNightly = "nightly" // the nightly rate times the nights, without fees
+)
+
+// Price of one listing for a given check-in date and stay length
+type PriceQuote struct {
+	ID           int       `json:"id" db:"id"`
+	ListingID    int       `json:"listing_id" db:"listing_id"`
+	CheckIn      time.Time `json:"check_in" db:"check_in"`
+	Nights       int       `json:"nights" db:"nights"`
+	Adults       int       `json:"adults" db:"adults"`
+	Available    bool      `json:"available" db:"available"`
+	TotalPrice   float64   `json:"total_price" db:"total_price"`     // 0 when the dates are not bookable
+	NightlyPrice float64   `json:"nightly_price" db:"nightly_price"` // total / nights
+	PriceBasis   string    `json:"price_basis" db:"price_basis"`     // PriceBasisTotal or PriceBasisNightly
+	QuotedAt     time.Time `json:"quoted_at" db:"quoted_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Scrape run kinds
+const (
+	RunKindFull  = "full"
+	RunKindArea  = "area"
+	RunKindWatch = "watch"
+	RunKindSeed  = "seed"
+)
+
+// One execution of the scraper
+type ScrapeRun struct {
+	ID            int        `json:"id" db:"id"`
+	Kind          string     `json:"kind" db:"kind"`
+	Status        string     `json:"status" db:"status"` // running, completed, failed
+	ListingsFound int        `json:"listings_found" db:"listings_found"`
+	ListingsSaved int        `json:"listings_saved" db:"listings_saved"`
+	StartedAt     time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at" db:"finished_at"`
+
+	// Health metrics
+	PagesScraped     int     `json:"pages_scraped" db:"pages_scraped"`
+	CardsFound       int     `json:"cards_found" db:"cards_found"`
+	DetailsAttempted int     `json:"details_attempted" db:"details_attempted"`
+	DetailsSucceeded int     `json:"details_succeeded" db:"details_succeeded"`
+	EmptyFieldShare  float64 `json:"empty_field_share" db:"empty_field_share"`
+	HealthIssues     string  `json:"health_issues" db:"health_issues"` // one issue per line, empty if healthy
+}
+
+// Price and capacity of a listing as captured by one scrape run
+type ListingSnapshot struct {
+	ID          int       `json:"id" db:"id"`
+	RunID       int       `json:"run_id" db:"run_id"` // 0 for snapshots backfilled from existing rows
+	ListingID   int       `json:"listing_id" db:"listing_id"`
+	Price       float64   `json:"price" db:"price"`
+	Rating      float64   `json:"rating" db:"rating"`
+	ReviewCount int       `json:"review_count" db:"review_count"`
+	Bedrooms    int       `json:"bedrooms" db:"bedrooms"`
+	Bathrooms   int       `json:"bathrooms" db:"bathrooms"`
+	Guests      int       `json:"guests" db:"guests"`
+	CapturedAt  time.Time `json:"captured_at" db:"captured_at"`
+
+	// Listing fields joined for reporting
+	Title             string    `json:"title" db:"-"`
+	URL               string    `json:"url" db:"-"`
+	Location          string    `json:"location" db:"-"`
+	City              string    `json:"city" db:"-"`
+	CanonicalLocation string    `json:"canonical_location" db:"-"`
+	ListingCreatedAt  time.Time `json:"listing_created_at" db:"-"` // when the listing was first stored
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// A listing tracked by the watchlist refresh
+type WatchedListing struct {
+	ID              int        `json:"id" db:"id"`
+	ListingID       string     `json:"listing_id" db:"listing_id"` // Airbnb room ID
+	URL             string     `json:"url" db:"url"`
+	Note            string     `json:"note" db:"note"`
+	AddedAt         time.Time  `json:"added_at" db:"added_at"`
+	LastRefreshedAt *time.Time `json:"last_refreshed_at" db:"last_refreshed_at"`
+	Title           string     `json:"title" db:"-"` // from the stored listing, empty until first scraped
+	Price           float64    `json:"price" db:"-"`
+}
@@ -0,0 +1,191 @@
+package airbnb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// CalendarResult holds the availability calendar scraped for one listing URL
+type CalendarResult struct {
+	URL   string
+	Days  []models.CalendarDay
+	Error error
+}
+
+// calendarJS requests the calendar months of a listing from the page's own origin
+// Arguments: API key (empty = read from the page), listing ID, first month, year, month count
+const calendarJS = `
+	(() => {
+		let key = %q;
+		if (!key) {
+			const html = document.documentElement.innerHTML;
+			const match = html.match(/"api_config":\{"key":"([a-z0-9]+)"/) || html.match(/"key":"([a-z0-9]{32})"/);
+			if (!match) {
+				return JSON.stringify({ error: 'no API key on page' });
+			}
+			key = match[1];
+		}
+		const xhr = new XMLHttpRequest();
+		xhr.open('GET', '/api/v2/calendar_months?_format=with_conditions&currency=USD&locale=en' +
+			'&listing_id=%s&month=%d&year=%d&count=%d&key=' + key, false);
+		xhr.send();
+		return xhr.status === 200 ? xhr.responseText : JSON.stringify({ error: 'HTTP ' + xhr.status });
+	})()
+`
+
+// ScrapeCalendar fetches availability and nightly prices for the next days nights of a listing
+func (s *Scraper) ScrapeCalendar(ctx context.Context, url string, days int) ([]models.CalendarDay, error) {
+	listingID := utils.ExtractListingID(url)
+	if listingID == "" {
+		return nil, fmt.Errorf("no listing ID in %s", url)
+	}
+
+	browserCtx, cancel := s.createStealthContext(ctx)
+	defer cancel()
+
+	browserCtx, cancel = context.WithTimeout(browserCtx, 30*time.Second)
+	defer cancel()
+
+	s.logger.Info("Scraping calendar: %s", url)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	months := days/28 + 2 // calendar months overlap at their edges; ask for enough to cover the window
+	script := fmt.Sprintf(calendarJS, s.cfg.CalendarAPIKey, listingID, int(today.Month()), today.Year(), months)
+
+	var calendarJSON string
+	err := chromedp.Run(browserCtx,
+		removeWebdriverProperty(),
+		chromedp.Navigate(utils.CanonicalListingURL(listingID)),
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.Evaluate(script, &calendarJSON),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load calendar: %w", err)
+	}
+
+	var response struct {
+		Error          string `json:"error"`
+		CalendarMonths []struct {
+			Days []struct {
+				Date      string `json:"date"`
+				Available bool   `json:"available"`
+				MinNights int    `json:"min_nights"`
+				Price     struct {
+					LocalPrice float64 `json:"local_price"`
+				} `json:"price"`
+			} `json:"days"`
+		} `json:"calendar_months"`
+	}
+
+	if err := json.Unmarshal([]byte(calendarJSON), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse calendar JSON: %w", err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("calendar request failed: %s", response.Error)
+	}
+
+	end := today.AddDate(0, 0, days)
+	seen := make(map[string]bool)
+	calendar := []models.CalendarDay{}
+
+	for _, month := range response.CalendarMonths {
+		for _, day := range month.Days {
+			date, err := time.Parse("2006-01-02", day.Date)
+			if err != nil || seen[day.Date] || date.Before(today) || !date.Before(end) {
+				continue
+			}
+			seen[day.Date] = true
+
+			calendar = append(calendar, models.CalendarDay{
+				Date:      date,
+				Available: day.Available,
+				Price:     day.Price.LocalPrice,
+				MinNights: day.MinNights,
+			})
+		}
+	}
+
+	if len(calendar) == 0 {
+		return nil, fmt.Errorf("calendar returned no nights")
+	}
+
+	s.logger.Success("Calendar scraped: %d nights", len(calendar))
+	return calendar, nil
+}
+
+// ScrapeCalendarsWithWorkers scrapes the calendars of multiple listings concurrently using worker pool
+func (s *Scraper) ScrapeCalendarsWithWorkers(ctx context.Context, urls []string, days int) map[string]*CalendarResult {
+	results := make(map[string]*CalendarResult)
+	resultsMux := &sync.Mutex{}
+
+	urlChan := make(chan string, len(urls))
+	var wg sync.WaitGroup
+
+	numWorkers := s.cfg.MaxWorkers
+	if numWorkers <= 0 {
+		numWorkers = 3 // Default
+	}
+
+	s.logger.Info("Starting %d workers for calendar scraping...", numWorkers)
+
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			for url := range urlChan {
+				s.logger.Info("[Worker %d] Calendar: %s", workerID, url)
+				result := s.scrapeCalendarWithRetry(ctx, url, days)
+
+				resultsMux.Lock()
+				results[url] = result
+				resultsMux.Unlock()
+			}
+		}(i + 1)
+	}
+
+	for _, url := range urls {
+		urlChan <- url
+	}
+	close(urlChan)
+
+	wg.Wait()
+
+	s.logger.Success("All calendars scraped")
+	return results
+}
+
+// scrapeCalendarWithRetry attempts to scrape a calendar with retries
+func (s *Scraper) scrapeCalendarWithRetry(ctx context.Context, url string, days int) *CalendarResult {
+	maxRetries := s.cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3 // Default
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		calendar, err := s.ScrapeCalendar(ctx, url, days)
+		if err == nil {
+			return &CalendarResult{URL: url, Days: calendar}
+		}
+		lastErr = err
+
+		if attempt < maxRetries {
+			s.logger.Warning("Calendar attempt %d/%d failed for %s: %v. Retrying...", attempt, maxRetries, url, err)
+			time.Sleep(time.Duration(s.cfg.RetryDelayMs) * time.Millisecond)
+		}
+	}
+
+	s.logger.Error("Failed to scrape calendar %s after %d attempts: %v", url, maxRetries, lastErr)
+	return &CalendarResult{
+		URL:   url,
+		Error: fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr),
+	}
+}
@@ -4,21 +4,42 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/chromedp/chromedp"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
 )
 
 // DetailResult holds the result of scraping a detail page
 type DetailResult struct {
-	URL       string
-	Bedrooms  int
-	Bathrooms int
-	Guests    int
-	Error     error
+	URL          string
+	Guests       int
+	Bedrooms     int // 0 for studios
+	Beds         int
+	Bathrooms    int    // full baths; "1.5 baths" is 1 full and 1 half bath
+	HalfBaths    int    // baths without a shower or tub
+	BathroomType string // "private", "shared" or empty when the page does not say
+	Studio       bool
+	Confidence   models.DetailConfidence
+	Latitude     float64 // 0 when the page has no map data
+	Longitude    float64
+	Amenities    int // 0 when the page has no amenities button
+	Error        error
 }
 
+// Detail field confidences
+const (
+	confidenceListed   = 1.0 // a separate item of the overview list
+	confidenceOverview = 0.9 // a "·"-separated part of the overview text
+	confidenceSleeping = 0.8 // counted from the sleeping arrangements only
+	confidenceConflict = 0.7 // the overview and the sleeping arrangements disagree; the overview wins
+)
+
 // ScrapeDetailPage extracts bedroom, bathroom, and guest info from a listing detail page
 func (s *Scraper) ScrapeDetailPage(ctx context.Context, url string) (*DetailResult, error) {
 	result := &DetailResult{URL: url}
@@ -48,43 +69,7 @@ func (s *Scraper) ScrapeDetailPage(ctx context.Context, url string) (*DetailResu
 		chromedp.WaitVisible(`[data-section-id="OVERVIEW_DEFAULT"]`, chromedp.ByQuery),
 
 		// Extract details using JavaScript
-		chromedp.Evaluate(`
-			JSON.stringify({
-				bedrooms: (() => {
-					// Try multiple selectors for bedrooms
-					const bedroomText = Array.from(document.querySelectorAll('li, span, div'))
-						.map(el => el.innerText)
-						.find(text => /(\d+)\s*(bedroom|bed)/i.test(text));
-					if (bedroomText) {
-						const match = bedroomText.match(/(\d+)\s*(bedroom|bed)/i);
-						return match ? parseInt(match[1]) : 0;
-					}
-					return 0;
-				})(),
-				bathrooms: (() => {
-					// Try multiple selectors for bathrooms
-					const bathroomText = Array.from(document.querySelectorAll('li, span, div'))
-						.map(el => el.innerText)
-						.find(text => /(\d+\.?\d*)\s*bath/i.test(text));
-					if (bathroomText) {
-						const match = bathroomText.match(/(\d+\.?\d*)\s*bath/i);
-						return match ? parseFloat(match[1]) : 0;
-					}
-					return 0;
-				})(),
-				guests: (() => {
-					// Try multiple selectors for guests
-					const guestText = Array.from(document.querySelectorAll('li, span, div'))
-						.map(el => el.innerText)
-						.find(text => /(\d+)\s*guest/i.test(text));
-					if (guestText) {
-						const match = guestText.match(/(\d+)\s*guest/i);
-						return match ? parseInt(match[1]) : 0;
-					}
-					return 0;
-				})()
-			})
-		`, &detailsJSON),
+		chromedp.Evaluate(extractDetailsJS, &detailsJSON),
 	)
 
 	if err != nil {
@@ -92,24 +77,15 @@ func (s *Scraper) ScrapeDetailPage(ctx context.Context, url string) (*DetailResu
 		return result, result.Error
 	}
 
-	// Parse the JSON response
-	var details struct {
-		Bedrooms  int     `json:"bedrooms"`
-		Bathrooms float64 `json:"bathrooms"`
-		Guests    int     `json:"guests"`
-	}
-
-	if err := json.Unmarshal([]byte(detailsJSON), &details); err != nil {
-		result.Error = fmt.Errorf("failed to parse details JSON: %w", err)
+	if err := parseDetails(detailsJSON, result); err != nil {
+		result.Error = err
 		return result, result.Error
 	}
 
-	result.Bedrooms = details.Bedrooms
-	result.Bathrooms = int(details.Bathrooms) // Convert to int for storage
-	result.Guests = details.Guests
-
-	s.logger.Success("Detail page scraped: %d beds, %d baths, %d guests",
-		result.Bedrooms, result.Bathrooms, result.Guests)
+	s.logger.Success("Detail page scraped: %s", result.Summary())
+	if fields := result.conflicts(); len(fields) > 0 {
+		s.logger.Warning("Overview and sleeping arrangements disagree on %s for %s; kept the overview", strings.Join(fields, " and "), url)
+	}
 
 	return result, nil
 }
@@ -201,3 +177,208 @@ func (s *Scraper) scrapeDetailWithRetry(ctx context.Context, url string) *Detail
 		Error: fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr),
 	}
 }
+
+// Summary describes the capacity read from the page: "2 bedrooms, 3 beds, 1.5 shared baths, 4 guests"
+func (r *DetailResult) Summary() string {
+	rooms := fmt.Sprintf("%d bedrooms", r.Bedrooms)
+	if r.Studio {
+		rooms = "studio"
+	}
+
+	baths := strconv.Itoa(r.Bathrooms)
+	if r.HalfBaths > 0 {
+		baths = strconv.FormatFloat(float64(r.Bathrooms)+0.5*float64(r.HalfBaths), 'f', -1, 64)
+	}
+	if r.BathroomType != "" {
+		baths += " " + r.BathroomType
+	}
+
+	return fmt.Sprintf("%s, %d beds, %s baths, %d guests", rooms, r.Beds, baths, r.Guests)
+}
+
+// conflicts lists the fields whose overview value disagrees with the sleeping arrangements
+func (r *DetailResult) conflicts() []string {
+	fields := []string{}
+	if r.Confidence.Bedrooms == confidenceConflict {
+		fields = append(fields, "bedrooms")
+	}
+	if r.Confidence.Beds == confidenceConflict {
+		fields = append(fields, "beds")
+	}
+	return fields
+}
+
+// extractDetailsJS reads the overview list, sleeping arrangements, coordinates and amenity count
+// of a listing detail page
+// The overview under the title reads "4 guests · 2 bedrooms · 3 beds · 1.5 baths"; it is returned
+// item by item when the page marks it up as a list, otherwise as its text.
+const extractDetailsJS = `
+	(() => {
+		const overview = document.querySelector('[data-section-id="OVERVIEW_DEFAULT"]');
+		const items = overview ? Array.from(overview.querySelectorAll('ol li, ul li')).map(li => li.innerText.trim()) : [];
+		const text = overview ? (overview.querySelector('ol, ul') || overview).innerText : '';
+
+		// "Where you'll sleep": one card per room, "Bedroom 1" over "1 queen bed, 1 single bed"
+		const sleeping = document.querySelector('[data-section-id="SLEEPING_ARRANGEMENT_DEFAULT"], [data-section-id="SLEEPING_ARRANGEMENT_WITH_IMAGES"]');
+		const linesOf = (el) => el.innerText.split('\n').map(line => line.trim()).filter(Boolean);
+		const isRoom = (el) => {
+			const lines = linesOf(el);
+			return lines.length >= 2 && !/bed\b/i.test(lines[0]) && lines.slice(1).some(line => /\d+\s.*bed/i.test(line));
+		};
+		// The innermost elements holding a room name over its beds are the room cards
+		const rooms = sleeping ? Array.from(sleeping.querySelectorAll('div, li'))
+			.filter(el => isRoom(el) && !Array.from(el.children).some(isRoom))
+			.map(el => {
+				const lines = linesOf(el);
+				return { room: lines[0], beds: lines.slice(1).join(', ') };
+			}) : [];
+
+		return JSON.stringify({
+			overview: items.filter(Boolean),
+			overviewText: items.length ? '' : text,
+			sleeping: rooms,
+			coordinates: (() => {
+				// The location section embeds the map position in the page data
+				const html = document.documentElement.innerHTML;
+				const match = html.match(/"lat":(-?\d+\.\d+),"lng":(-?\d+\.\d+)/) ||
+					html.match(/"latitude":(-?\d+\.\d+),"longitude":(-?\d+\.\d+)/);
+				return match ? { lat: parseFloat(match[1]), lng: parseFloat(match[2]) } : { lat: 0, lng: 0 };
+			})(),
+			amenities: (() => {
+				// The amenities section previews a few and links to the full list: "Show all 42 amenities"
+				const match = document.body.innerText.match(/show all (\d+) amenities/i);
+				return match ? parseInt(match[1]) : 0;
+			})()
+		});
+	})()
+`
+
+// Overview items, matched whole so review text and titles never count
+var (
+	guestsRegex   = regexp.MustCompile(`(?i)^(\d+)\+?\s+guests?$`)
+	bedroomsRegex = regexp.MustCompile(`(?i)^(\d+)\s+bedrooms?$`)
+	bedsRegex     = regexp.MustCompile(`(?i)^(\d+)\s+beds?$`)
+	studioRegex   = regexp.MustCompile(`(?i)^studio$`)
+
+	// "1 bath", "1.5 baths", "2 shared baths", "1 private attached bathroom", "Half-bath", "Shared half-bath"
+	bathsRegex = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?\s+)?(shared|private|dedicated)?\s*(?:attached\s+)?(half[- ]?)?bath(?:room)?s?$`)
+
+	// A bed line of the sleeping arrangements: "1 queen bed", "2 single beds", "1 sofa bed"
+	bedCountRegex = regexp.MustCompile(`(?i)(\d+)\s+[a-z -]*?\bbeds?\b`)
+)
+
+// parseDetails fills a detail result from the JSON returned by extractDetailsJS
+func parseDetails(detailsJSON string, result *DetailResult) error {
+	var details struct {
+		Overview     []string `json:"overview"`
+		OverviewText string   `json:"overviewText"`
+		Amenities    int      `json:"amenities"`
+
+		Sleeping []struct {
+			Room string `json:"room"`
+			Beds string `json:"beds"`
+		} `json:"sleeping"`
+
+		Coordinates struct {
+			Lat float64 `json:"lat"`
+			Lng float64 `json:"lng"`
+		} `json:"coordinates"`
+	}
+
+	if err := json.Unmarshal([]byte(detailsJSON), &details); err != nil {
+		return fmt.Errorf("failed to parse details JSON: %w", err)
+	}
+
+	items, confidence := details.Overview, confidenceListed
+	if len(items) == 0 {
+		items, confidence = splitOverview(details.OverviewText), confidenceOverview
+	}
+	parseOverview(items, confidence, result)
+
+	if len(details.Sleeping) > 0 {
+		bedrooms, beds := 0, 0
+		for _, room := range details.Sleeping {
+			if strings.HasPrefix(strings.ToLower(room.Room), "bedroom") {
+				bedrooms++
+			}
+			for _, match := range bedCountRegex.FindAllStringSubmatch(room.Beds, -1) {
+				n, _ := strconv.Atoi(match[1])
+				beds += n
+			}
+		}
+		crossCheck(&result.Bedrooms, &result.Confidence.Bedrooms, bedrooms, !result.Studio)
+		crossCheck(&result.Beds, &result.Confidence.Beds, beds, true)
+	}
+
+	result.Latitude = details.Coordinates.Lat
+	result.Longitude = details.Coordinates.Lng
+	result.Amenities = details.Amenities
+	return nil
+}
+
+// splitOverview splits the overview text "4 guests · 2 bedrooms · 3 beds · 1 bath" into its items
+func splitOverview(text string) []string {
+	items := []string{}
+	for _, item := range strings.FieldsFunc(text, func(r rune) bool { return r == '·' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseOverview reads guests, bedrooms, beds and baths from the overview items
+func parseOverview(items []string, confidence float64, result *DetailResult) {
+	for _, item := range items {
+		switch {
+		case guestsRegex.MatchString(item):
+			result.Guests, _ = strconv.Atoi(guestsRegex.FindStringSubmatch(item)[1])
+			result.Confidence.Guests = confidence
+		case studioRegex.MatchString(item):
+			result.Studio, result.Bedrooms = true, 0
+			result.Confidence.Bedrooms = confidence
+		case bedroomsRegex.MatchString(item):
+			result.Bedrooms, _ = strconv.Atoi(bedroomsRegex.FindStringSubmatch(item)[1])
+			result.Confidence.Bedrooms = confidence
+		case bedsRegex.MatchString(item):
+			result.Beds, _ = strconv.Atoi(bedsRegex.FindStringSubmatch(item)[1])
+			result.Confidence.Beds = confidence
+		case bathsRegex.MatchString(item):
+			match := bathsRegex.FindStringSubmatch(item)
+			count := 1.0 // "Half-bath", "Private bath"
+			if match[1] != "" {
+				count, _ = strconv.ParseFloat(strings.TrimSpace(match[1]), 64)
+			}
+			if match[3] != "" {
+				result.Bathrooms, result.HalfBaths = 0, int(count) // "2 half-baths"
+			} else {
+				result.Bathrooms, result.HalfBaths = int(count), 0
+				if count > float64(int(count)) {
+					result.HalfBaths = 1 // "1.5 baths"
+				}
+			}
+			switch strings.ToLower(match[2]) {
+			case "shared":
+				result.BathroomType = "shared"
+			case "private", "dedicated":
+				result.BathroomType = "private"
+			}
+			result.Confidence.Bathrooms = confidence
+		}
+	}
+}
+
+// crossCheck reconciles a field read from the overview with the count from the sleeping arrangements
+// The sleeping count fills a missing value; when both are known and differ the overview is kept
+// with a lower confidence. Returns without change when the count is not applicable.
+func crossCheck(value *int, confidence *float64, count int, applicable bool) {
+	if !applicable || count == 0 {
+		return
+	}
+	switch {
+	case *confidence == 0:
+		*value, *confidence = count, confidenceSleeping
+	case *value != count:
+		*confidence = math.Min(*confidence, confidenceConflict)
+	}
+}
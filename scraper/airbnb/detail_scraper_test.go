@@ -0,0 +1,121 @@
+package airbnb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+func TestSplitOverview(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"4 guests · 2 bedrooms · 3 beds · 1.5 baths", []string{"4 guests", "2 bedrooms", "3 beds", "1.5 baths"}},
+		{"2 guests\n· Studio\n·1 bed · 1 shared bath", []string{"2 guests", "Studio", "1 bed", "1 shared bath"}},
+		{" · ", []string{}},
+		{"", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := splitOverview(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitOverview(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseOverview(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []string
+		want  DetailResult
+	}{
+		{
+			name:  "full overview",
+			items: []string{"4 guests", "2 bedrooms", "3 beds", "1.5 baths"},
+			want: DetailResult{
+				Guests: 4, Bedrooms: 2, Beds: 3, Bathrooms: 1, HalfBaths: 1,
+				Confidence: models.DetailConfidence{Guests: 1, Bedrooms: 1, Beds: 1, Bathrooms: 1},
+			},
+		},
+		{
+			name:  "studio with shared bath",
+			items: []string{"2 guests", "Studio", "1 bed", "1 shared bath"},
+			want: DetailResult{
+				Guests: 2, Studio: true, Beds: 1, Bathrooms: 1, BathroomType: "shared",
+				Confidence: models.DetailConfidence{Guests: 1, Bedrooms: 1, Beds: 1, Bathrooms: 1},
+			},
+		},
+		{
+			name:  "half baths only",
+			items: []string{"16+ guests", "2 half-baths"},
+			want: DetailResult{
+				Guests: 16, HalfBaths: 2,
+				Confidence: models.DetailConfidence{Guests: 1, Bathrooms: 1},
+			},
+		},
+		{
+			name:  "private attached bathroom",
+			items: []string{"1 bedroom", "1 private attached bathroom"},
+			want: DetailResult{
+				Bedrooms: 1, Bathrooms: 1, BathroomType: "private",
+				Confidence: models.DetailConfidence{Bedrooms: 1, Bathrooms: 1},
+			},
+		},
+		{
+			name:  "review text is ignored",
+			items: []string{"Great place with 3 bedrooms and 2 beds", "Guest favorite"},
+			want:  DetailResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetailResult{}
+			parseOverview(tt.items, confidenceListed, &got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOverview(%q) = %+v, want %+v", tt.items, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOverviewConfidence(t *testing.T) {
+	got := DetailResult{}
+	parseOverview(splitOverview("3 guests · 1 bedroom"), confidenceOverview, &got)
+
+	want := models.DetailConfidence{Guests: confidenceOverview, Bedrooms: confidenceOverview}
+	if got.Confidence != want {
+		t.Errorf("confidence = %+v, want %+v", got.Confidence, want)
+	}
+}
+
+func TestCrossCheck(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          int
+		confidence     float64
+		count          int
+		applicable     bool
+		wantValue      int
+		wantConfidence float64
+	}{
+		{"fills a missing value", 0, 0, 2, true, 2, confidenceSleeping},
+		{"agreeing values keep confidence", 3, confidenceListed, 3, true, 3, confidenceListed},
+		{"conflict keeps the overview", 2, confidenceListed, 3, true, 2, confidenceConflict},
+		{"conflict never raises confidence", 2, 0.5, 3, true, 2, 0.5},
+		{"no sleeping count", 2, confidenceListed, 0, true, 2, confidenceListed},
+		{"not applicable to studios", 0, confidenceListed, 1, false, 0, confidenceListed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, confidence := tt.value, tt.confidence
+			crossCheck(&value, &confidence, tt.count, tt.applicable)
+			if value != tt.wantValue || confidence != tt.wantConfidence {
+				t.Errorf("crossCheck = (%d, %v), want (%d, %v)", value, confidence, tt.wantValue, tt.wantConfidence)
+			}
+		})
+	}
+}
@@ -4,17 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/chromedp/chromedp"
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
 )
 
+// LocationCard is a crawlable search discovered on the homepage
 type LocationCard struct {
-	Name string
-	URL  string
+	Kind   string // config.DiscoveryLocation, DiscoveryCategory or DiscoveryInspiration
+	Group  string // inspiration tab ("Popular", "Beach"), empty otherwise
+	Region string // second line of an inspiration card, usually the region or country
+	Name   string
+	URL    string
 }
 
-// ScrapeHomepageLocations extracts all visible location cards from Airbnb homepage
+// ScrapeHomepageLocations extracts the location links, category tabs and
+// "Inspiration for future getaways" destinations from the Airbnb homepage
+// A search reachable from several places is returned once, under the first kind found.
 func (s *Scraper) ScrapeHomepageLocations(ctx context.Context) ([]LocationCard, error) {
 	browserCtx, cancel := s.createStealthContext(ctx)
 	defer cancel()
@@ -36,19 +46,24 @@ func (s *Scraper) ScrapeHomepageLocations(ctx context.Context) ([]LocationCard,
 		chromedp.Evaluate(`window.scrollTo(0, 0)`, nil),
 		chromedp.Sleep(2*time.Second),
 
-		// Extract all location cards
+		// Extract all location cards; inspiration destinations are read with their tab below
 		chromedp.Evaluate(`
-			JSON.stringify(
-				Array.from(document.querySelectorAll('a[href*="/s/"]')).map(link => ({
-					name: link.innerText.trim() || link.getAttribute('aria-label') || 'Unknown',
-					url: link.href
-				})).filter(loc => 
-					loc.url.includes('/s/') && 
-					loc.url.includes('/homes') &&
-					loc.name !== '' &&
-					loc.name !== 'Unknown'
-				).slice(0, 20)
-			)
+			(() => {
+				const inspiration = `+inspirationSectionJS+`;
+				return JSON.stringify(
+					Array.from(document.querySelectorAll('a[href*="/s/"]')).filter(link =>
+						!(inspiration && inspiration.contains(link))
+					).map(link => ({
+						name: link.innerText.trim() || link.getAttribute('aria-label') || 'Unknown',
+						url: link.href
+					})).filter(loc =>
+						loc.url.includes('/s/') &&
+						loc.url.includes('/homes') &&
+						loc.name !== '' &&
+						loc.name !== 'Unknown'
+					)
+				);
+			})()
 		`, &locationsJSON),
 	)
 
@@ -69,21 +84,223 @@ func (s *Scraper) ScrapeHomepageLocations(ctx context.Context) ([]LocationCard,
 	// Convert to LocationCard structs and deduplicate
 	locations := []LocationCard{}
 	seen := make(map[string]bool)
+	add := func(card LocationCard) {
+		key := card.URL
+		if parsed, err := url.Parse(card.URL); err == nil {
+			key = parsed.Path + "?" + parsed.Query().Get("category_tag")
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		locations = append(locations, card)
+	}
 
 	for _, raw := range rawLocations {
-		// Deduplicate by URL
-		if seen[raw.URL] {
-			continue
+		add(LocationCard{Kind: config.DiscoveryLocation, Name: raw.Name, URL: raw.URL})
+	}
+
+	// Clicking category tabs filters the homepage, so the inspiration section is read first
+	inspiration, err := s.scrapeInspiration(browserCtx)
+	if err != nil {
+		s.logger.Warning("Failed to read inspiration destinations: %v", err)
+	}
+
+	categories, err := s.scrapeCategoryTabs(browserCtx)
+	if err != nil {
+		s.logger.Warning("Failed to read category tabs: %v", err)
+	}
+
+	for _, card := range categories {
+		add(card)
+	}
+	for _, card := range inspiration {
+		add(card)
+	}
+
+	s.logger.Success("Found %d unique locations on homepage (%d category tabs, %d inspiration destinations)",
+		len(locations), len(categories), len(inspiration))
+
+	return locations, nil
+}
+
+// scrapeCategoryTabs reads the category bar (Beachfront, Cabins, Countryside...)
+// Tabs without a link are clicked one by one and the category tag is read from the updated address.
+// Each category is crawled as a search over every location: /s/homes?category_tag=Tag:789
+func (s *Scraper) scrapeCategoryTabs(browserCtx context.Context) ([]LocationCard, error) {
+	var tabsJSON string
+	err := chromedp.Run(browserCtx,
+		chromedp.Evaluate(`window.scrollTo(0, 0)`, nil),
+		chromedp.Sleep(time.Second),
+		chromedp.Evaluate(categoryTabsJS, &tabsJSON),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var tabs []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(tabsJSON), &tabs); err != nil {
+		return nil, fmt.Errorf("failed to parse category tabs JSON: %w", err)
+	}
+
+	cards := []LocationCard{}
+	for i, tab := range tabs {
+		pageURL := tab.URL
+		if pageURL == "" {
+			err := chromedp.Run(browserCtx,
+				chromedp.Evaluate(fmt.Sprintf(`(() => { const tabs = %s; if (tabs[%d]) tabs[%d].click(); })()`, categoryTabsSelectorJS, i, i), nil),
+				chromedp.Sleep(1500*time.Millisecond),
+				chromedp.Location(&pageURL),
+			)
+			if err != nil {
+				return cards, fmt.Errorf("failed to open category %s: %w", tab.Name, err)
+			}
 		}
-		seen[raw.URL] = true
 
-		locations = append(locations, LocationCard{
-			Name: raw.Name,
-			URL:  raw.URL,
+		tag := categoryTag(pageURL)
+		if tag == "" || tab.Name == "" {
+			continue
+		}
+		cards = append(cards, LocationCard{
+			Kind: config.DiscoveryCategory,
+			Name: utils.CleanText(tab.Name),
+			URL:  strings.TrimSuffix(s.cfg.BaseURL, "/") + "/s/homes?" + url.Values{"category_tag": {tag}}.Encode(),
 		})
 	}
+	return cards, nil
+}
+
+// categoryTag returns the category_tag parameter of a URL, empty if it has none
+func categoryTag(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Query().Get("category_tag")
+}
 
-	s.logger.Success("Found %d unique locations on homepage", len(locations))
+// categoryTabsSelectorJS evaluates to the category bar tabs in display order
+const categoryTabsSelectorJS = `Array.from(document.querySelectorAll(
+	'[data-testid="category-bar-filter"] label, [data-testid^="category-item"], [aria-label*="ategor"] [role="radio"]'
+))`
 
-	return locations, nil
+// categoryTabsJS lists the category tabs with their name and, when the tab is a link, its URL
+const categoryTabsJS = `
+	JSON.stringify(` + categoryTabsSelectorJS + `.map(tab => {
+		const img = tab.querySelector('img');
+		const link = tab.closest('a') || tab.querySelector('a');
+		return {
+			name: tab.innerText.trim() || (img ? img.getAttribute('alt') : '') || '',
+			url: link && link.href.includes('category_tag') ? link.href : ''
+		};
+	}))
+`
+
+// scrapeInspiration reads every tab of the "Inspiration for future getaways" section
+// Each tab (Popular, Arts & culture, Beach...) lists destinations as "City / Region" cards
+func (s *Scraper) scrapeInspiration(browserCtx context.Context) ([]LocationCard, error) {
+	var tabNames []string
+	var tabsJSON string
+	err := chromedp.Run(browserCtx,
+		chromedp.Evaluate(`window.scrollTo(0, document.body.scrollHeight)`, nil),
+		chromedp.Sleep(2*time.Second),
+		chromedp.Evaluate(`JSON.stringify(`+inspirationTabsJS+`.map(tab => tab.innerText.trim()))`, &tabsJSON),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tabsJSON), &tabNames); err != nil {
+		return nil, fmt.Errorf("failed to parse inspiration tabs JSON: %w", err)
+	}
+	if len(tabNames) == 0 {
+		tabNames = []string{""} // a section without tabs still has one panel
+	}
+
+	cards := []LocationCard{}
+	for i, group := range tabNames {
+		var destinationsJSON string
+		err := chromedp.Run(browserCtx,
+			chromedp.Evaluate(fmt.Sprintf(`(() => { const tabs = %s; if (tabs[%d]) tabs[%d].click(); })()`, inspirationTabsJS, i, i), nil),
+			chromedp.Sleep(time.Second),
+			chromedp.Evaluate(inspirationShowMoreJS, nil),
+			chromedp.Sleep(time.Second),
+			chromedp.Evaluate(inspirationDestinationsJS, &destinationsJSON),
+		)
+		if err != nil {
+			return cards, fmt.Errorf("failed to read inspiration tab %q: %w", group, err)
+		}
+
+		var destinations []struct {
+			Name   string `json:"name"`
+			Region string `json:"region"`
+			URL    string `json:"url"`
+		}
+		if err := json.Unmarshal([]byte(destinationsJSON), &destinations); err != nil {
+			return cards, fmt.Errorf("failed to parse inspiration JSON: %w", err)
+		}
+
+		for _, d := range destinations {
+			card := LocationCard{
+				Kind:   config.DiscoveryInspiration,
+				Group:  utils.CleanText(group),
+				Region: utils.CleanText(d.Region),
+				Name:   utils.CleanText(d.Name),
+				URL:    d.URL,
+			}
+			if card.Name == "" || card.URL == "" {
+				continue
+			}
+			// Some destinations link to a landing page (/canmore-canada/stays) rather than a search
+			if !strings.Contains(card.URL, "/s/") {
+				card.URL = s.searchURLFor(card.Name, card.Region)
+			}
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
 }
+
+// searchURLFor builds the search URL of a place: "Canmore", "Alberta" -> /s/Canmore--Alberta/homes
+func (s *Scraper) searchURLFor(name, region string) string {
+	place := name
+	if region != "" {
+		place += "--" + region
+	}
+	place = strings.ReplaceAll(place, " ", "-")
+	return strings.TrimSuffix(s.cfg.BaseURL, "/") + "/s/" + url.PathEscape(place) + "/homes"
+}
+
+// inspirationSectionJS evaluates to the section holding the "Inspiration for future getaways" heading
+const inspirationSectionJS = `(() => {
+	const heading = Array.from(document.querySelectorAll('h2, h3')).find(h => /inspiration/i.test(h.innerText));
+	return heading ? (heading.closest('section') || heading.parentElement.parentElement) : null;
+})()`
+
+// inspirationTabsJS evaluates to the tabs of the inspiration section
+const inspirationTabsJS = `(() => {
+	const section = ` + inspirationSectionJS + `;
+	return section ? Array.from(section.querySelectorAll('[role="tab"]')) : [];
+})()`
+
+// inspirationShowMoreJS expands the open tab when it hides destinations behind "Show more"
+const inspirationShowMoreJS = `(() => {
+	const section = ` + inspirationSectionJS + `;
+	if (!section) return;
+	const more = Array.from(section.querySelectorAll('button')).find(b => /show more/i.test(b.innerText));
+	if (more) more.click();
+})()`
+
+// inspirationDestinationsJS lists the destination links of the open inspiration tab
+// A card reads "Canmore\nAlberta": the first line is the destination, the second its region
+const inspirationDestinationsJS = `(() => {
+	const section = ` + inspirationSectionJS + `;
+	if (!section) return '[]';
+	const panel = section.querySelector('[role="tabpanel"]:not([hidden])') || section;
+	return JSON.stringify(Array.from(panel.querySelectorAll('a[href]')).map(link => {
+		const lines = link.innerText.split('\n').map(line => line.trim()).filter(Boolean);
+		return { name: lines[0] || '', region: lines[1] || '', url: link.href };
+	}));
+})()`
@@ -0,0 +1,194 @@
+package airbnb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// QuoteRequest is one listing, check-in date and stay length of a date sweep
+type QuoteRequest struct {
+	URL     string
+	CheckIn time.Time
+	Nights  int
+}
+
+// QuoteResult holds the price a listing page showed for a quote request
+type QuoteResult struct {
+	QuoteRequest
+	Available  bool
+	TotalPrice float64 // total of the stay, see PriceBasis
+	PriceBasis string  // models.PriceBasisTotal or models.PriceBasisNightly
+	Error      error
+}
+
+// extractQuoteJS reads the total and nightly price from the booking panel of a listing page
+// Only the panel is read, so prices elsewhere on the page (similar listings, reviews) are never taken
+const extractQuoteJS = `
+	(() => {
+		const panel = document.querySelector('[data-section-id="BOOK_IT_SIDEBAR"]') ||
+			document.querySelector('[data-testid="book-it-default"]');
+		if (!panel) return JSON.stringify({ panel: false });
+		const text = panel.innerText;
+		const total = text.match(/total[^\n$€£]*([$€£]\s?[\d,]+(?:\.\d+)?)/i) ||
+			text.match(/([$€£]\s?[\d,]+(?:\.\d+)?)\s*total/i);
+		const night = text.match(/([$€£]\s?[\d,]+(?:\.\d+)?)\s*(?:\/|per)?\s*night/i);
+		return JSON.stringify({
+			panel: true,
+			total: total ? total[1] : '',
+			night: night ? night[1] : '',
+			unavailable: /not available|unavailable|minimum stay|dates are taken/i.test(text)
+		});
+	})()
+`
+
+// bookingQuote is the booking panel as read by extractQuoteJS
+type bookingQuote struct {
+	Panel       bool   `json:"panel"`
+	Total       string `json:"total"`
+	Night       string `json:"night"`
+	Unavailable bool   `json:"unavailable"`
+}
+
+// quoteURL returns the listing page URL preset to a check-in date, stay length and guest count
+func quoteURL(listingURL string, checkIn time.Time, nights, adults int) (string, error) {
+	listingID := utils.ExtractListingID(listingURL)
+	if listingID == "" {
+		return "", fmt.Errorf("no listing ID in %s", listingURL)
+	}
+
+	query := url.Values{}
+	query.Set("check_in", checkIn.Format("2006-01-02"))
+	query.Set("check_out", checkIn.AddDate(0, 0, nights).Format("2006-01-02"))
+	query.Set("adults", strconv.Itoa(adults))
+	return utils.CanonicalListingURL(listingID) + "?" + query.Encode(), nil
+}
+
+// ScrapeQuote loads a listing page for the requested dates and reads the price of the stay
+func (s *Scraper) ScrapeQuote(ctx context.Context, req QuoteRequest, adults int) (*QuoteResult, error) {
+	result := &QuoteResult{QuoteRequest: req}
+
+	pageURL, err := quoteURL(req.URL, req.CheckIn, req.Nights, adults)
+	if err != nil {
+		return result, err
+	}
+
+	browserCtx, cancel := s.createStealthContext(ctx)
+	defer cancel()
+
+	browserCtx, cancel = context.WithTimeout(browserCtx, 30*time.Second)
+	defer cancel()
+
+	s.logger.Info("Quoting %s", pageURL)
+
+	var quoteJSON string
+	err = chromedp.Run(browserCtx,
+		removeWebdriverProperty(),
+		chromedp.Navigate(pageURL),
+		chromedp.WaitVisible(`[data-section-id="OVERVIEW_DEFAULT"]`, chromedp.ByQuery),
+		chromedp.Sleep(2*time.Second), // the booking panel prices the dates after the page renders
+		chromedp.Evaluate(extractQuoteJS, &quoteJSON),
+	)
+	if err != nil {
+		return result, fmt.Errorf("failed to load listing page: %w", err)
+	}
+
+	var quote bookingQuote
+	if err := json.Unmarshal([]byte(quoteJSON), &quote); err != nil {
+		return result, fmt.Errorf("failed to parse quote JSON: %w", err)
+	}
+	if !quote.Panel {
+		return result, fmt.Errorf("booking panel not found on %s", pageURL)
+	}
+
+	result.TotalPrice = utils.NormalizePrice(quote.Total)
+	result.PriceBasis = models.PriceBasisTotal
+	if result.TotalPrice == 0 {
+		result.TotalPrice = utils.NormalizePrice(quote.Night) * float64(req.Nights)
+		result.PriceBasis = models.PriceBasisNightly
+	}
+	result.Available = !quote.Unavailable && result.TotalPrice > 0
+
+	if result.Available {
+		s.logger.Success("Quote: %s, %d nights = $%.2f (%s)", req.CheckIn.Format("2006-01-02"), req.Nights, result.TotalPrice, result.PriceBasis)
+	} else {
+		result.TotalPrice = 0
+		s.logger.Info("Not bookable: %s, %d nights", req.CheckIn.Format("2006-01-02"), req.Nights)
+	}
+
+	return result, nil
+}
+
+// ScrapeQuotesWithWorkers quotes multiple listing/date combinations concurrently using worker pool
+// Results are returned in the order of the requests
+func (s *Scraper) ScrapeQuotesWithWorkers(ctx context.Context, requests []QuoteRequest, adults int) []*QuoteResult {
+	results := make([]*QuoteResult, len(requests))
+
+	indexChan := make(chan int, len(requests))
+	var wg sync.WaitGroup
+
+	numWorkers := s.cfg.MaxWorkers
+	if numWorkers <= 0 {
+		numWorkers = 3 // Default
+	}
+
+	s.logger.Info("Starting %d workers for %d price quotes...", numWorkers, len(requests))
+
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			for index := range indexChan {
+				results[index] = s.scrapeQuoteWithRetry(ctx, requests[index], adults)
+				s.randomDelay()
+			}
+		}(i + 1)
+	}
+
+	for i := range requests {
+		indexChan <- i
+	}
+	close(indexChan)
+
+	wg.Wait()
+
+	s.logger.Success("All price quotes scraped")
+	return results
+}
+
+// scrapeQuoteWithRetry attempts to scrape a quote with retries
+func (s *Scraper) scrapeQuoteWithRetry(ctx context.Context, req QuoteRequest, adults int) *QuoteResult {
+	maxRetries := s.cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3 // Default
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		result, err := s.ScrapeQuote(ctx, req, adults)
+		if err == nil {
+			return result
+		}
+		lastErr = err
+
+		if attempt < maxRetries {
+			s.logger.Warning("Quote attempt %d/%d failed for %s: %v. Retrying...", attempt, maxRetries, req.URL, err)
+			time.Sleep(time.Duration(s.cfg.RetryDelayMs) * time.Millisecond)
+		}
+	}
+
+	s.logger.Error("Failed to quote %s after %d attempts: %v", req.URL, maxRetries, lastErr)
+	return &QuoteResult{
+		QuoteRequest: req,
+		Error:        fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr),
+	}
+}
@@ -2,8 +2,13 @@ package airbnb
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chromedp/chromedp"
@@ -16,6 +21,21 @@ import (
 type Scraper struct {
 	cfg    *config.ScraperConfig
 	logger *utils.Logger
+	stats  PageStats
+}
+
+// PageStats counts the search result pages visited and the cards found on them
+type PageStats struct {
+	Pages int
+	Cards int
+}
+
+// CardsPerPage returns the average number of cards per visited page
+func (p PageStats) CardsPerPage() float64 {
+	if p.Pages == 0 {
+		return 0
+	}
+	return float64(p.Cards) / float64(p.Pages)
 }
 
 // NewScraper creates a new Airbnb scraper instance
@@ -26,6 +46,11 @@ func NewScraper(cfg *config.ScraperConfig, logger *utils.Logger) *Scraper {
 	}
 }
 
+// Stats returns the search page counts accumulated since the scraper was created
+func (s *Scraper) Stats() PageStats {
+	return s.stats
+}
+
 // createStealthContext creates a browser context with anti-detection settings
 func (s *Scraper) createStealthContext(parentCtx context.Context) (context.Context, context.CancelFunc) {
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
@@ -58,187 +83,355 @@ func removeWebdriverProperty() chromedp.Action {
 }
 
 // ScrapeListings scrapes listings from a specific location URL
-// Returns first PropertiesPerPage listings from each of MaxPages pages
-// Returns first PropertiesPerPage listings from each of MaxPages pages
-func (s *Scraper) ScrapeListings(ctx context.Context, locationURL string) ([]models.RawListing, error) {
+// Pages are visited by rewriting the result offset in the search URL.
+// In limited mode the first PropertiesPerPage listings of each of MaxPages pages are kept;
+// in exhaust mode every card is collected until results run out or a page repeats.
+// A positive quota stops the crawl once that many listings were collected.
+func (s *Scraper) ScrapeListings(ctx context.Context, locationURL string, quota int) ([]models.RawListing, error) {
 	browserCtx, cancel := s.createStealthContext(ctx)
 	defer cancel()
 
-	s.logger.Info("Scraping location: %s", locationURL)
-	s.logger.Info("Target: %d properties per page × %d pages = %d total",
-		s.cfg.PropertiesPerPage, s.cfg.MaxPages, s.cfg.PropertiesPerPage*s.cfg.MaxPages)
+	exhaust := s.cfg.IsExhaustive()
 
-	allListings := []models.RawListing{}
+	s.logger.Info("Scraping location: %s", locationURL)
+	if exhaust {
+		s.logger.Info("Target: every page until results are exhausted")
+	} else {
+		s.logger.Info("Target: %d properties per page × %d pages = %d total",
+			s.cfg.PropertiesPerPage, s.cfg.MaxPages, s.cfg.PropertiesPerPage*s.cfg.MaxPages)
+	}
+	if quota > 0 {
+		s.logger.Info("Location quota: %d properties", quota)
+	}
 
-	// Navigate to first page
-	err := chromedp.Run(browserCtx,
-		removeWebdriverProperty(),
-		chromedp.Navigate(locationURL),
-		chromedp.WaitVisible(`[data-testid="card-container"]`, chromedp.ByQuery),
-		chromedp.Sleep(3*time.Second),
-	)
+	if err := chromedp.Run(browserCtx, removeWebdriverProperty()); err != nil {
+		return nil, fmt.Errorf("failed to prepare browser: %w", err)
+	}
 
+	allListings, err := s.paginate(browserCtx, locationURL, exhaust, quota)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load first page: %w", err)
+		return nil, err
 	}
 
-	// Scrape multiple pages
-	for page := 1; page <= s.cfg.MaxPages; page++ {
-		s.logger.Info("Scraping page %d/%d...", page, s.cfg.MaxPages)
-
-		// Extract listings using inline JavaScript
-		var listingsJSON string
-		err := chromedp.Run(browserCtx,
-			chromedp.Sleep(2*time.Second),
-			chromedp.Evaluate(`
-				JSON.stringify(
-					Array.from(document.querySelectorAll('[data-testid="card-container"]')).slice(0, 20).map(card => {
-						const getText = (selector) => {
-							const el = card.querySelector(selector);
-							return el ? el.innerText.trim() : '';
-						};
-						const getAttr = (selector, attr) => {
-							const el = card.querySelector(selector);
-							return el ? el.getAttribute(attr) : '';
-						};
-						return {
-							title: getText('[data-testid="listing-card-subtitle"]') || 
-							       getText('[itemprop="name"]') ||
-							       getText('div[id*="title"]'),
-							price: getText('[data-testid="price-availability-row"]') ||
-							       getText('span._tyxjp1') ||
-							       getText('span[aria-label*="price"]'),
-							location: getText('[data-testid="listing-card-title"]') ||
-							         getText('span[data-testid="listing-card-name"]'),
-							rating: getAttr('[aria-label*="rating"]', 'aria-label') ||
-							       getText('span[aria-label*="rating"]'),
-							url: card.querySelector('a') ? card.querySelector('a').href : '',
-							bedrooms: 0,
-							bathrooms: 0,
-							guests: 0
-						};
-					})
-				)
-			`, &listingsJSON),
-		)
+	s.logger.Success("Total listings scraped for this location: %d", len(allListings))
+	return allListings, nil
+}
+
+// paginate walks the result pages of a search URL in an already open browser context
+func (s *Scraper) paginate(browserCtx context.Context, searchURL string, exhaust bool, quota int) ([]models.RawListing, error) {
+	allListings := []models.RawListing{}
+	seenIDs := make(map[string]bool)
+	seenPages := make(map[string]bool)
 
+	for page := 1; exhaust || page <= s.cfg.MaxPages; page++ {
+		pageURL, err := buildPageURL(searchURL, (page-1)*s.cfg.PageSize)
 		if err != nil {
-			s.logger.Error("Failed to extract listings from page %d: %v", page, err)
-			continue
+			return allListings, fmt.Errorf("failed to build page URL: %w", err)
 		}
 
-		listings := s.parseListingsJSON(listingsJSON)
+		s.logger.Info("Scraping page %d: %s", page, pageURL)
 
-		// Limit to PropertiesPerPage (first 5)
-		if len(listings) > s.cfg.PropertiesPerPage {
-			listings = listings[:s.cfg.PropertiesPerPage]
+		listings, err := s.scrapeSearchPage(browserCtx, pageURL)
+		if err != nil {
+			if page == 1 {
+				// A first page without cards usually means a broken selector; count it for health checks
+				s.stats.Pages++
+				return nil, fmt.Errorf("failed to load first page: %w", err)
+			}
+			s.logger.Warning("Page %d returned no results, stopping: %v", page, err)
+			break
 		}
 
-		s.logger.Success("Scraped %d listings from page %d (limited to first %d)",
-			len(listings), page, s.cfg.PropertiesPerPage)
-		allListings = append(allListings, listings...)
-
-		// Navigate to next page if not last
-		if page < s.cfg.MaxPages {
-			time.Sleep(2 * time.Second)
-
-			var hasNext bool
-			s.logger.Info("Looking for 'Next' button...")
-
-			err = chromedp.Run(browserCtx,
-				// Check if next button exists
-				chromedp.Evaluate(`
-					(() => {
-						const nextButton = document.querySelector('a[aria-label="Next"]') ||
-						                   document.querySelector('a[aria-label*="next"]') ||
-						                   document.querySelector('nav a:last-child');
-						return nextButton && !nextButton.getAttribute('aria-disabled');
-					})()
-				`, &hasNext),
-			)
-
-			if err != nil {
-				s.logger.Error("Failed to check for next button: %v", err)
-				break
+		if len(listings) == 0 {
+			if page == 1 {
+				s.stats.Pages++
 			}
+			s.logger.Info("No more results after page %d", page-1)
+			break
+		}
 
-			if !hasNext {
-				s.logger.Info("No 'Next' button found, stopping at page %d", page)
-				break
-			}
+		// Airbnb serves the last page again once the offset runs past the results
+		signature := pageSignature(listings)
+		if seenPages[signature] {
+			s.logger.Info("Page %d repeats an earlier page, stopping", page)
+			break
+		}
+		seenPages[signature] = true
 
-			// Click next button
-			err = chromedp.Run(browserCtx,
-				chromedp.Click(`a[aria-label="Next"]`, chromedp.ByQuery),
-			)
-
-			if err != nil {
-				// Try alternative selector
-				s.logger.Warning("First selector failed, trying alternative...")
-				err = chromedp.Run(browserCtx,
-					chromedp.Click(`nav a:last-child`, chromedp.ByQuery),
-				)
-				if err != nil {
-					s.logger.Error("Failed to click next button: %v", err)
-					break
-				}
-			}
+		s.stats.Pages++
+		s.stats.Cards += len(listings)
 
-			s.logger.Success("Clicked 'Next' button")
+		// Limit to PropertiesPerPage unless we are exhausting results
+		if !exhaust && len(listings) > s.cfg.PropertiesPerPage {
+			listings = listings[:s.cfg.PropertiesPerPage]
+		}
+
+		added := 0
+		for _, listing := range listings {
+			key := utils.ExtractListingID(listing.URL)
+			if key == "" {
+				key = utils.NormalizeURL(listing.URL)
+			}
+			if seenIDs[key] {
+				continue
+			}
+			seenIDs[key] = true
 
-			// Wait for new page to load
-			err = chromedp.Run(browserCtx,
-				chromedp.Sleep(3*time.Second),
-				chromedp.WaitVisible(`[data-testid="card-container"]`, chromedp.ByQuery),
-			)
+			allListings = append(allListings, listing)
+			added++
 
-			if err != nil {
-				s.logger.Error("Failed waiting for next page: %v", err)
+			if quota > 0 && len(allListings) >= quota {
 				break
 			}
+		}
 
-			s.logger.Success("Page %d loaded", page+1)
+		s.logger.Success("Scraped %d new listings from page %d", added, page)
+
+		if quota > 0 && len(allListings) >= quota {
+			s.logger.Info("Reached location quota of %d", quota)
+			break
 		}
+
+		s.randomDelay()
 	}
 
-	s.logger.Success("Total listings scraped for this location: %d", len(allListings))
 	return allListings, nil
 }
 
-// goToNextPage clicks the pagination next button
-func (s *Scraper) goToNextPage(ctx context.Context) (bool, error) {
-	s.logger.Info("Looking for 'Next' button...")
+// scrapeSearchPage loads a single search results page and extracts its cards
+// The page is scrolled until every card has loaded, and the fields missing from its cards are reported.
+// Each page gets TimeoutSeconds; a page that shows no card within it has no results and returns none.
+func (s *Scraper) scrapeSearchPage(browserCtx context.Context, pageURL string) ([]models.RawListing, error) {
+	browserCtx, cancel := context.WithTimeout(browserCtx, time.Duration(s.cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
 
-	var nextButtonExists bool
-	err := chromedp.Evaluate(`
-		(() => {
-			const nextButton = document.querySelector('a[aria-label="Next"]') ||
-			                   document.querySelector('a[aria-label*="next"]') ||
-			                   document.querySelector('nav a:last-child');
-			return nextButton && !nextButton.getAttribute('aria-disabled');
-		})()
-	`, &nextButtonExists).Do(ctx)
+	err := chromedp.Run(browserCtx,
+		chromedp.Navigate(pageURL),
+		chromedp.WaitVisible(`[data-testid="card-container"]`, chromedp.ByQuery),
+	)
+	if err != nil {
+		if browserCtx.Err() == context.DeadlineExceeded {
+			s.logger.Info("No cards after %ds, treating the page as past the last result", s.cfg.TimeoutSeconds)
+			return nil, nil
+		}
+		return nil, err
+	}
 
+	state, err := s.loadAllCards(browserCtx)
 	if err != nil {
-		return false, fmt.Errorf("failed to check for next button: %w", err)
+		return nil, err
 	}
 
-	if !nextButtonExists {
-		return false, nil
+	var listingsJSON string
+	if err := chromedp.Run(browserCtx, chromedp.Evaluate(extractCardsJS, &listingsJSON)); err != nil {
+		return nil, err
 	}
 
-	// Click next button
-	err = chromedp.Click(`a[aria-label="Next"]`, chromedp.ByQuery).Do(ctx)
-	if err != nil {
-		// Try alternative
-		err = chromedp.Click(`nav a:last-child`, chromedp.ByQuery).Do(ctx)
+	listings := s.parseListingsJSON(listingsJSON)
+	s.reportMissingFields(pageURL, state, listings)
+	return listings, nil
+}
+
+// cardState is the loading progress of the cards on a results page
+type cardState struct {
+	Cards    int  `json:"cards"`
+	Ready    int  `json:"ready"` // cards showing a title and a price
+	AtBottom bool `json:"atBottom"`
+}
+
+// loadAllCards scrolls a results page until its card count stops growing at the bottom of the page,
+// waiting for the network to go idle after each scroll, then waits for every card to be ready
+func (s *Scraper) loadAllCards(browserCtx context.Context) (cardState, error) {
+	var state cardState
+	stable := 0
+	for round := 0; round < s.cfg.ScrollMaxRounds && stable < 2; round++ {
+		previous := state.Cards
+		if err := chromedp.Run(browserCtx, chromedp.Evaluate(`window.scrollBy(0, window.innerHeight)`, nil)); err != nil {
+			return state, fmt.Errorf("failed to scroll results: %w", err)
+		}
+		if err := s.waitNetworkIdle(browserCtx); err != nil {
+			return state, err
+		}
+		if err := chromedp.Run(browserCtx, chromedp.Evaluate(cardStateJS, &state)); err != nil {
+			return state, fmt.Errorf("failed to count cards: %w", err)
+		}
+
+		if state.AtBottom && state.Cards == previous {
+			stable++
+		} else {
+			stable = 0
+		}
+	}
+
+	// Cards render before their prices hydrate
+	deadline := time.Now().Add(time.Duration(s.cfg.CardReadyTimeoutMs) * time.Millisecond)
+	for state.Ready < state.Cards && time.Now().Before(deadline) {
+		time.Sleep(250 * time.Millisecond)
+		if err := chromedp.Run(browserCtx, chromedp.Evaluate(cardStateJS, &state)); err != nil {
+			return state, fmt.Errorf("failed to check card readiness: %w", err)
+		}
+	}
+	if state.Ready < state.Cards {
+		s.logger.Warning("%d of %d cards still incomplete after %dms", state.Cards-state.Ready, state.Cards, s.cfg.CardReadyTimeoutMs)
+	}
+
+	return state, nil
+}
+
+// waitNetworkIdle waits until no request has completed for NetworkIdleMs
+// Pages that never go quiet (polling, analytics) are given up on after ten idle periods.
+func (s *Scraper) waitNetworkIdle(browserCtx context.Context) error {
+	quiet := time.Duration(s.cfg.NetworkIdleMs) * time.Millisecond
+	deadline := time.Now().Add(10 * quiet)
+
+	last, lastChange := -1, time.Now()
+	for {
+		var count int
+		err := chromedp.Run(browserCtx, chromedp.Evaluate(`
+			performance.setResourceTimingBufferSize(100000);
+			performance.getEntriesByType('resource').length
+		`, &count))
 		if err != nil {
-			return false, fmt.Errorf("failed to click next button: %w", err)
+			return fmt.Errorf("failed to watch network activity: %w", err)
+		}
+
+		now := time.Now()
+		if count != last {
+			last, lastChange = count, now
+		}
+		if now.Sub(lastChange) >= quiet || now.After(deadline) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// reportMissingFields logs the fields left empty on the cards of a page
+// Cards without a title or URL are dropped by parseListingsJSON and reported as such.
+// A missing rating is not reported: new listings have none yet.
+func (s *Scraper) reportMissingFields(pageURL string, state cardState, listings []models.RawListing) {
+	missing := []string{}
+	if dropped := state.Cards - len(listings); dropped > 0 {
+		missing = append(missing, fmt.Sprintf("title/URL %d (dropped)", dropped))
+	}
+
+	counts := map[string]int{}
+	for _, listing := range listings {
+		if listing.Price == "" {
+			counts["price"]++
+		}
+		if listing.Location == "" {
+			counts["location"]++
+		}
+	}
+	for _, field := range []string{"price", "location"} {
+		if counts[field] > 0 {
+			missing = append(missing, fmt.Sprintf("%s %d", field, counts[field]))
 		}
 	}
 
-	s.logger.Success("Navigated to next page")
-	return true, nil
+	if len(missing) == 0 {
+		s.logger.Info("All %d cards complete on %s", state.Cards, pageURL)
+		return
+	}
+	s.logger.Warning("%d cards on %s, missing fields: %s", state.Cards, pageURL, strings.Join(missing, ", "))
+}
+
+// cardStateJS counts the cards of a results page and those already showing a title and a price
+const cardStateJS = `
+	(() => {
+		const cards = Array.from(document.querySelectorAll('[data-testid="card-container"]'));
+		const text = (card, selector) => {
+			const el = card.querySelector(selector);
+			return el ? el.innerText.trim() : '';
+		};
+		const ready = cards.filter(card => {
+			const title = text(card, '[data-testid="listing-card-subtitle"]') || text(card, '[data-testid="listing-card-title"]');
+			const price = text(card, '[data-testid="price-availability-row"]') || text(card, 'span._tyxjp1') ||
+			              text(card, 'span[aria-label*="price"]');
+			return title !== '' && /\d/.test(price);
+		}).length;
+		return {
+			cards: cards.length,
+			ready: ready,
+			atBottom: window.innerHeight + window.scrollY >= document.body.scrollHeight - 2
+		};
+	})()
+`
+
+// extractCardsJS collects the visible listing cards of a search results page
+const extractCardsJS = `
+	JSON.stringify(
+		Array.from(document.querySelectorAll('[data-testid="card-container"]')).map(card => {
+			const getText = (selector) => {
+				const el = card.querySelector(selector);
+				return el ? el.innerText.trim() : '';
+			};
+			const getAttr = (selector, attr) => {
+				const el = card.querySelector(selector);
+				return el ? el.getAttribute(attr) : '';
+			};
+			return {
+				title: getText('[data-testid="listing-card-subtitle"]') || 
+				       getText('[itemprop="name"]') ||
+				       getText('div[id*="title"]'),
+				price: getText('[data-testid="price-availability-row"]') ||
+				       getText('span._tyxjp1') ||
+				       getText('span[aria-label*="price"]'),
+				location: getText('[data-testid="listing-card-title"]') ||
+				         getText('span[data-testid="listing-card-name"]'),
+				rating: getAttr('[aria-label*="rating"]', 'aria-label') ||
+				       getText('span[aria-label*="rating"]'),
+				url: card.querySelector('a') ? card.querySelector('a').href : '',
+				bedrooms: 0,
+				bathrooms: 0,
+				guests: 0
+			};
+		})
+	)
+`
+
+// buildPageURL returns the search URL for the page starting at the given result offset
+// Airbnb reads both items_offset and the base64 cursor, so both are set
+func buildPageURL(locationURL string, offset int) (string, error) {
+	parsed, err := url.Parse(locationURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsed.Query()
+	query.Del("cursor")
+	query.Del("items_offset")
+	query.Del("pagination_search")
+
+	if offset > 0 {
+		cursor := fmt.Sprintf(`{"section_offset":0,"items_offset":%d,"version":1}`, offset)
+		query.Set("pagination_search", "true")
+		query.Set("items_offset", strconv.Itoa(offset))
+		query.Set("cursor", base64.StdEncoding.EncodeToString([]byte(cursor)))
+	}
+
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// pageSignature identifies a results page by the listings it contains
+func pageSignature(listings []models.RawListing) string {
+	urls := make([]string, 0, len(listings))
+	for _, listing := range listings {
+		urls = append(urls, utils.NormalizeURL(listing.URL))
+	}
+	return strings.Join(urls, "|")
+}
+
+// randomDelay sleeps for a random duration between DelayMinMs and DelayMaxMs
+func (s *Scraper) randomDelay() {
+	minMs, maxMs := s.cfg.DelayMinMs, s.cfg.DelayMaxMs
+	if maxMs <= minMs {
+		time.Sleep(time.Duration(minMs) * time.Millisecond)
+		return
+	}
+	time.Sleep(time.Duration(minMs+rand.Intn(maxMs-minMs)) * time.Millisecond)
 }
 
 // parseListingsJSON parses JSON into RawListing structs
@@ -0,0 +1,185 @@
+package airbnb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// Crawl seed kinds
+const (
+	SeedSearch   = "search"   // a saved search: /s/<place>/homes?<filters>
+	SeedWishlist = "wishlist" // a shared wishlist: /wishlists/<id>
+)
+
+// SeedKind returns whether a URL is a saved search or a wishlist
+func SeedKind(seedURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(seedURL))
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid seed URL %q", seedURL)
+	}
+
+	switch {
+	case strings.HasPrefix(parsed.Path, "/s/"):
+		return SeedSearch, nil
+	case strings.Contains(parsed.Path, "/wishlists/"):
+		return SeedWishlist, nil
+	}
+	return "", fmt.Errorf("%s is neither a search nor a wishlist URL", seedURL)
+}
+
+// SeedLocation returns the place name of a saved search URL, empty for wishlists
+// "/s/Melbourne--Victoria--Australia/homes" -> "Melbourne, Victoria, Australia"
+func SeedLocation(seedURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(seedURL))
+	if err != nil || !strings.HasPrefix(parsed.Path, "/s/") {
+		return ""
+	}
+
+	if query := parsed.Query().Get("query"); query != "" {
+		return utils.CleanText(query)
+	}
+
+	place := strings.Split(strings.TrimPrefix(parsed.Path, "/s/"), "/")[0]
+	if place == "" || place == "homes" {
+		return ""
+	}
+	place = strings.ReplaceAll(place, "--", ", ")
+	return utils.CleanText(strings.ReplaceAll(place, "-", " "))
+}
+
+// ExpandSeed collects the listings behind a saved search or wishlist URL
+// Saved searches keep their filters and are paginated until results run out.
+// Wishlists are a single page that is scrolled until no more listings load; their
+// cards only show prices when the wishlist has dates.
+func (s *Scraper) ExpandSeed(ctx context.Context, seedURL string) ([]models.RawListing, error) {
+	kind, err := SeedKind(seedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	browserCtx, cancel := s.createStealthContext(ctx)
+	defer cancel()
+
+	if err := chromedp.Run(browserCtx, removeWebdriverProperty()); err != nil {
+		return nil, fmt.Errorf("failed to prepare browser: %w", err)
+	}
+
+	s.logger.Info("Expanding %s seed: %s", kind, seedURL)
+
+	var listings []models.RawListing
+	if kind == SeedSearch {
+		listings, err = s.paginate(browserCtx, seedURL, true, 0)
+	} else {
+		listings, err = s.scrapeWishlist(browserCtx, seedURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	location := SeedLocation(seedURL)
+	for i := range listings {
+		listings[i].SearchLocation = location
+	}
+
+	s.logger.Success("Seed expanded to %d listings", len(listings))
+	return listings, nil
+}
+
+// scrapeWishlist loads a wishlist, scrolls until its listing count stops growing and extracts the listings
+// A wishlist that shows no listing within TimeoutSeconds (empty, private or deleted) is an error.
+func (s *Scraper) scrapeWishlist(browserCtx context.Context, wishlistURL string) ([]models.RawListing, error) {
+	loadCtx, cancel := context.WithTimeout(browserCtx, time.Duration(s.cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	err := chromedp.Run(loadCtx,
+		chromedp.Navigate(wishlistURL),
+		chromedp.WaitVisible(`a[href*="/rooms/"]`, chromedp.ByQuery),
+		chromedp.Sleep(2*time.Second),
+	)
+	if err != nil {
+		s.stats.Pages++ // an empty or private wishlist counts for health checks
+		if loadCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("wishlist showed no listings after %ds; it may be empty, private or deleted", s.cfg.TimeoutSeconds)
+		}
+		return nil, fmt.Errorf("failed to load wishlist: %w", err)
+	}
+
+	// Wishlists load more listings as the page scrolls
+	count, stable := 0, 0
+	for round := 0; stable < 2 && round < 50; round++ {
+		var current int
+		err := chromedp.Run(browserCtx,
+			chromedp.Evaluate(`window.scrollTo(0, document.body.scrollHeight)`, nil),
+			chromedp.Sleep(1500*time.Millisecond),
+			chromedp.Evaluate(`new Set(Array.from(document.querySelectorAll('a[href*="/rooms/"]')).map(a => a.pathname)).size`, &current),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scroll wishlist: %w", err)
+		}
+		if current > count {
+			count, stable = current, 0
+		} else {
+			stable++
+		}
+	}
+
+	var listingsJSON string
+	if err := chromedp.Run(browserCtx, chromedp.Evaluate(extractWishlistJS, &listingsJSON)); err != nil {
+		return nil, fmt.Errorf("failed to extract wishlist: %w", err)
+	}
+
+	listings := s.parseListingsJSON(listingsJSON)
+	s.stats.Pages++
+	s.stats.Cards += len(listings)
+	return listings, nil
+}
+
+// extractWishlistJS collects one entry per listing linked from a wishlist page
+// Wishlist cards share the search card markup but are not always wrapped in a card container,
+// so each room link is read together with its closest card element
+const extractWishlistJS = `
+	(() => {
+		const seen = new Set();
+		const listings = [];
+		for (const link of document.querySelectorAll('a[href*="/rooms/"]')) {
+			if (seen.has(link.pathname)) continue;
+			seen.add(link.pathname);
+
+			const card = link.closest('[data-testid="card-container"]') ||
+			             link.closest('[itemprop="itemListElement"]') ||
+			             link.parentElement;
+			const getText = (selector) => {
+				const el = card.querySelector(selector);
+				return el ? el.innerText.trim() : '';
+			};
+			const getAttr = (selector, attr) => {
+				const el = card.querySelector(selector);
+				return el ? el.getAttribute(attr) : '';
+			};
+			listings.push({
+				title: getText('[data-testid="listing-card-subtitle"]') ||
+				       getText('[itemprop="name"]') ||
+				       getText('div[id*="title"]') ||
+				       link.getAttribute('aria-label') || '',
+				price: getText('[data-testid="price-availability-row"]') ||
+				       getText('span[aria-label*="price"]'),
+				location: getText('[data-testid="listing-card-title"]') ||
+				         getText('span[data-testid="listing-card-name"]'),
+				rating: getAttr('[aria-label*="rating"]', 'aria-label') ||
+				       getText('span[aria-label*="rating"]'),
+				url: link.href,
+				bedrooms: 0,
+				bathrooms: 0,
+				guests: 0
+			});
+		}
+		return JSON.stringify(listings);
+	})()
+`
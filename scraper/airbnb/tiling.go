@@ -0,0 +1,255 @@
+package airbnb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// BoundingBox is a map area in decimal degrees
+type BoundingBox struct {
+	South float64
+	West  float64
+	North float64
+	East  float64
+}
+
+// TileCrawlResult holds the outcome of a map-tiling crawl
+type TileCrawlResult struct {
+	Listings      []models.RawListing
+	TilesSearched int
+	TilesSplit    int
+	CappedTiles   int // tiles still over the cap at max depth
+}
+
+// tile is a bounding box waiting to be searched
+type tile struct {
+	box   BoundingBox
+	depth int
+}
+
+// ParseBoundingBox parses "south,west,north,east" into a BoundingBox
+func ParseBoundingBox(raw string) (BoundingBox, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, fmt.Errorf("bounding box must be south,west,north,east")
+	}
+
+	values := make([]float64, 4)
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BoundingBox{}, fmt.Errorf("invalid coordinate %q: %w", part, err)
+		}
+		values[i] = value
+	}
+
+	box := BoundingBox{South: values[0], West: values[1], North: values[2], East: values[3]}
+	if box.South >= box.North || box.West >= box.East {
+		return BoundingBox{}, fmt.Errorf("bounding box south/west must be below north/east")
+	}
+
+	return box, nil
+}
+
+// Quadrants splits the box into four equal tiles
+func (b BoundingBox) Quadrants() []BoundingBox {
+	midLat := (b.South + b.North) / 2
+	midLng := (b.West + b.East) / 2
+
+	return []BoundingBox{
+		{South: b.South, West: b.West, North: midLat, East: midLng},
+		{South: b.South, West: midLng, North: midLat, East: b.East},
+		{South: midLat, West: b.West, North: b.North, East: midLng},
+		{South: midLat, West: midLng, North: b.North, East: b.East},
+	}
+}
+
+// String formats the box the same way ParseBoundingBox reads it
+func (b BoundingBox) String() string {
+	return fmt.Sprintf("%.5f,%.5f,%.5f,%.5f", b.South, b.West, b.North, b.East)
+}
+
+// CrawlArea enumerates every listing inside a bounding box.
+// Each tile is searched with map bounds; tiles whose result count reaches
+// TileResultCap are split into quadrants until TileMaxDepth is reached.
+// Listings are deduplicated by room ID across tiles.
+func (s *Scraper) CrawlArea(ctx context.Context, searchURL string, box BoundingBox) (*TileCrawlResult, error) {
+	browserCtx, cancel := s.createStealthContext(ctx)
+	defer cancel()
+
+	if err := chromedp.Run(browserCtx, removeWebdriverProperty()); err != nil {
+		return nil, fmt.Errorf("failed to prepare browser: %w", err)
+	}
+
+	s.logger.Info("Tiling crawl of %s (cap %d results per tile, max depth %d)",
+		box, s.cfg.TileResultCap, s.cfg.TileMaxDepth)
+
+	result := &TileCrawlResult{}
+	seen := make(map[string]bool)
+	queue := []tile{{box: box}}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		tileURL, err := buildTileURL(searchURL, current.box)
+		if err != nil {
+			return result, fmt.Errorf("failed to build tile URL: %w", err)
+		}
+
+		count, over, known, err := s.tileResultCount(browserCtx, tileURL)
+		if err != nil {
+			s.logger.Warning("Failed to read result count for tile %s: %v", current.box, err)
+			continue
+		}
+		result.TilesSearched++
+
+		// Without a count the tile is crawled, and split afterwards if it turns out to fill the cap
+		if !known {
+			s.logger.Warning("Could not read the result count of tile %s, crawling it", current.box)
+			listings, err := s.paginate(browserCtx, tileURL, true, 0)
+			if err != nil {
+				s.logger.Warning("Failed to scrape tile %s: %v", current.box, err)
+				continue
+			}
+			if len(listings) >= s.cfg.TileResultCap && current.depth < s.cfg.TileMaxDepth {
+				s.logger.Info("Tile %s returned %d+ listings, splitting (depth %d)", current.box, len(listings), current.depth+1)
+				for _, quadrant := range current.box.Quadrants() {
+					queue = append(queue, tile{box: quadrant, depth: current.depth + 1})
+				}
+				result.TilesSplit++
+			} else if len(listings) >= s.cfg.TileResultCap {
+				s.logger.Warning("Tile %s still returns %d+ listings at max depth, results will be incomplete", current.box, len(listings))
+				result.CappedTiles++
+			}
+			added := addNewListings(result, seen, listings)
+			s.logger.Success("Tile %s: %d listings, %d new (%d total)",
+				current.box, len(listings), added, len(result.Listings))
+			continue
+		}
+
+		capped := over || count >= s.cfg.TileResultCap
+		if capped && current.depth < s.cfg.TileMaxDepth {
+			s.logger.Info("Tile %s has %d+ results, splitting (depth %d)", current.box, count, current.depth+1)
+			for _, quadrant := range current.box.Quadrants() {
+				queue = append(queue, tile{box: quadrant, depth: current.depth + 1})
+			}
+			result.TilesSplit++
+			continue
+		}
+
+		if capped {
+			s.logger.Warning("Tile %s still has %d+ results at max depth, results will be incomplete", current.box, count)
+			result.CappedTiles++
+		}
+
+		if count == 0 && !over {
+			continue
+		}
+
+		listings, err := s.paginate(browserCtx, tileURL, true, 0)
+		if err != nil {
+			s.logger.Warning("Failed to scrape tile %s: %v", current.box, err)
+			continue
+		}
+
+		added := addNewListings(result, seen, listings)
+		s.logger.Success("Tile %s: %d results, %d new listings (%d total)",
+			current.box, count, added, len(result.Listings))
+	}
+
+	s.logger.Success("Tiling crawl complete: %d unique listings from %d tiles",
+		len(result.Listings), result.TilesSearched)
+
+	return result, nil
+}
+
+// addNewListings appends the listings not seen in an earlier tile and returns how many were new
+func addNewListings(result *TileCrawlResult, seen map[string]bool, listings []models.RawListing) int {
+	added := 0
+	for _, listing := range listings {
+		id := utils.ExtractListingID(listing.URL)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result.Listings = append(result.Listings, listing)
+		added++
+	}
+	return added
+}
+
+// tileResultCount loads a tile and reads the "N homes" heading
+// over is true when Airbnb reports "Over N" or "N+" instead of an exact count,
+// known is false when the heading could not be read
+func (s *Scraper) tileResultCount(browserCtx context.Context, tileURL string) (count int, over, known bool, err error) {
+	var heading string
+
+	err = chromedp.Run(browserCtx,
+		chromedp.Navigate(tileURL),
+		chromedp.Sleep(4*time.Second),
+		chromedp.Evaluate(`
+			(() => {
+				const heading = document.querySelector('[data-testid="stays-page-heading"]') ||
+				                document.querySelector('main h1') ||
+				                document.querySelector('h1');
+				return heading ? heading.innerText.trim() : '';
+			})()
+		`, &heading),
+	)
+	if err != nil {
+		return 0, false, false, err
+	}
+
+	count, over, known = parseResultCount(heading)
+	return count, over, known, nil
+}
+
+var resultCountRegex = regexp.MustCompile(`(?i)(over\s+)?([\d,]+)(\+)?\s*(homes|stays|places|rentals|results)`)
+
+var noResultsRegex = regexp.MustCompile(`(?i)no (exact )?(matches|results)`)
+
+// parseResultCount extracts the result count from headings like "Over 1,000 homes" or "254 stays"
+// "No exact matches" is a known count of zero; any other heading is reported as unknown.
+func parseResultCount(heading string) (count int, over, known bool) {
+	match := resultCountRegex.FindStringSubmatch(heading)
+	if match == nil {
+		return 0, false, noResultsRegex.MatchString(heading)
+	}
+
+	count, err := strconv.Atoi(strings.ReplaceAll(match[2], ",", ""))
+	if err != nil {
+		return 0, false, false
+	}
+
+	return count, match[1] != "" || match[3] != "", true
+}
+
+// buildTileURL restricts a search URL to the given map bounds
+func buildTileURL(searchURL string, box BoundingBox) (string, error) {
+	parsed, err := url.Parse(searchURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsed.Query()
+	query.Del("place_id")
+	query.Set("search_by_map", "true")
+	query.Set("search_type", "user_map_move")
+	query.Set("sw_lat", strconv.FormatFloat(box.South, 'f', 6, 64))
+	query.Set("sw_lng", strconv.FormatFloat(box.West, 'f', 6, 64))
+	query.Set("ne_lat", strconv.FormatFloat(box.North, 'f', 6, 64))
+	query.Set("ne_lng", strconv.FormatFloat(box.East, 'f', 6, 64))
+
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
@@ -0,0 +1,188 @@
+package airbnb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// WatchResult holds a watched listing as read from its detail page
+type WatchResult struct {
+	URL     string
+	Listing models.RawListing
+	Error   error
+}
+
+// extractHeaderJS reads the title, property headline and rating of a listing detail page
+const extractHeaderJS = `
+	(() => {
+		const text = (selector) => {
+			const el = document.querySelector(selector);
+			return el ? el.innerText.trim() : '';
+		};
+		const body = document.body.innerText;
+		const rating = body.match(/(\d\.\d{1,2})\s*·\s*([\d,]+)\s*reviews?/i);
+		return JSON.stringify({
+			title: text('h1'),
+			headline: text('[data-section-id="OVERVIEW_DEFAULT"] h2') || text('h2'),
+			rating: rating ? rating[1] + ' (' + rating[2] + ')' : ''
+		});
+	})()
+`
+
+// ScrapeWatchedListing reads a listing's detail page for a reference stay
+// The price is the nightly rate the booking panel shows for the dates, the same basis as search card
+// prices, so fees never leak into listing prices and snapshots. It is empty when the dates cannot
+// be booked or the panel shows no nightly rate.
+func (s *Scraper) ScrapeWatchedListing(ctx context.Context, url string, checkIn time.Time, nights, adults int) (*models.RawListing, error) {
+	pageURL, err := quoteURL(url, checkIn, nights, adults)
+	if err != nil {
+		return nil, err
+	}
+
+	browserCtx, cancel := s.createStealthContext(ctx)
+	defer cancel()
+
+	browserCtx, cancel = context.WithTimeout(browserCtx, 30*time.Second)
+	defer cancel()
+
+	s.logger.Info("Refreshing watched listing: %s", pageURL)
+
+	var headerJSON, detailsJSON, quoteJSON string
+	err = chromedp.Run(browserCtx,
+		removeWebdriverProperty(),
+		chromedp.Navigate(pageURL),
+		chromedp.WaitVisible(`[data-section-id="OVERVIEW_DEFAULT"]`, chromedp.ByQuery),
+		chromedp.Sleep(2*time.Second), // the booking panel prices the dates after the page renders
+		chromedp.Evaluate(extractHeaderJS, &headerJSON),
+		chromedp.Evaluate(extractDetailsJS, &detailsJSON),
+		chromedp.Evaluate(extractQuoteJS, &quoteJSON),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load listing page: %w", err)
+	}
+
+	var header struct {
+		Title    string `json:"title"`
+		Headline string `json:"headline"`
+		Rating   string `json:"rating"`
+	}
+	if err := json.Unmarshal([]byte(headerJSON), &header); err != nil {
+		return nil, fmt.Errorf("failed to parse header JSON: %w", err)
+	}
+
+	details := &DetailResult{URL: url}
+	if err := parseDetails(detailsJSON, details); err != nil {
+		return nil, err
+	}
+
+	var quote bookingQuote
+	if err := json.Unmarshal([]byte(quoteJSON), &quote); err != nil {
+		return nil, fmt.Errorf("failed to parse quote JSON: %w", err)
+	}
+
+	price := ""
+	if quote.Panel && !quote.Unavailable {
+		if nightly := utils.NormalizePrice(quote.Night); nightly > 0 {
+			price = fmt.Sprintf("$%.2f", nightly)
+		}
+	}
+
+	listing := &models.RawListing{
+		Title:        utils.CleanText(header.Title),
+		Price:        price,
+		Location:     utils.CleanText(header.Headline),
+		Rating:       header.Rating,
+		URL:          url,
+		Bedrooms:     details.Bedrooms,
+		Beds:         details.Beds,
+		Bathrooms:    details.Bathrooms,
+		HalfBaths:    details.HalfBaths,
+		BathroomType: details.BathroomType,
+		Studio:       details.Studio,
+		Guests:       details.Guests,
+		Confidence:   details.Confidence,
+		Latitude:     details.Latitude,
+		Longitude:    details.Longitude,
+		Amenities:    details.Amenities,
+	}
+
+	s.logger.Success("Watched listing refreshed: %s %s", listing.Title, price)
+	return listing, nil
+}
+
+// ScrapeWatchlistWithWorkers refreshes multiple watched listings concurrently using worker pool
+func (s *Scraper) ScrapeWatchlistWithWorkers(ctx context.Context, urls []string, checkIn time.Time, nights, adults int) map[string]*WatchResult {
+	results := make(map[string]*WatchResult)
+	resultsMux := &sync.Mutex{}
+
+	urlChan := make(chan string, len(urls))
+	var wg sync.WaitGroup
+
+	numWorkers := s.cfg.MaxWorkers
+	if numWorkers <= 0 {
+		numWorkers = 3 // Default
+	}
+
+	s.logger.Info("Starting %d workers for %d watched listings...", numWorkers, len(urls))
+
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			for url := range urlChan {
+				s.logger.Info("[Worker %d] Watched: %s", workerID, url)
+				result := s.scrapeWatchedWithRetry(ctx, url, checkIn, nights, adults)
+
+				resultsMux.Lock()
+				results[url] = result
+				resultsMux.Unlock()
+			}
+		}(i + 1)
+	}
+
+	for _, url := range urls {
+		urlChan <- url
+	}
+	close(urlChan)
+
+	wg.Wait()
+
+	s.logger.Success("All watched listings refreshed")
+	return results
+}
+
+// scrapeWatchedWithRetry attempts to refresh a watched listing with retries
+func (s *Scraper) scrapeWatchedWithRetry(ctx context.Context, url string, checkIn time.Time, nights, adults int) *WatchResult {
+	maxRetries := s.cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3 // Default
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		listing, err := s.ScrapeWatchedListing(ctx, url, checkIn, nights, adults)
+		if err == nil {
+			return &WatchResult{URL: url, Listing: *listing}
+		}
+		lastErr = err
+
+		if attempt < maxRetries {
+			s.logger.Warning("Watch attempt %d/%d failed for %s: %v. Retrying...", attempt, maxRetries, url, err)
+			time.Sleep(time.Duration(s.cfg.RetryDelayMs) * time.Millisecond)
+		}
+	}
+
+	s.logger.Error("Failed to refresh %s after %d attempts: %v", url, maxRetries, lastErr)
+	return &WatchResult{
+		URL:   url,
+		Error: fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr),
+	}
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// AlertService evaluates the configured alert rules after a scrape run and delivers new alerts
+type AlertService struct {
+	db        *storage.DB
+	logger    *utils.Logger
+	cfg       *config.AlertsConfig
+	notifiers []Notifier
+}
+
+// NewAlertService creates an alert service with a notifier for every configured channel
+func NewAlertService(db *storage.DB, logger *utils.Logger, cfg *config.AlertsConfig) *AlertService {
+	notifiers := []Notifier{}
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(cfg.WebhookURL))
+	}
+	if cfg.SMTP.Host != "" && len(cfg.SMTP.To) > 0 {
+		notifiers = append(notifiers, NewSMTPNotifier(cfg.SMTP))
+	}
+
+	return &AlertService{
+		db:        db,
+		logger:    logger,
+		cfg:       cfg,
+		notifiers: notifiers,
+	}
+}
+
+// Evaluate checks every rule against a finished run
+// locationCounts maps each location searched by the run to the number of listings it returned
+func (s *AlertService) Evaluate(run *models.ScrapeRun, locationCounts map[string]int) ([]models.Alert, error) {
+	current, err := s.db.GetSnapshotsForRun(run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousList, err := s.db.GetPreviousSnapshots(run.ID)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[int]*models.ListingSnapshot, len(previousList))
+	for i := range previousList {
+		previous[previousList[i].ListingID] = &previousList[i]
+	}
+
+	now := time.Now()
+	alerts := []models.Alert{}
+
+	for _, rule := range s.cfg.Rules {
+		switch rule.Type {
+		case config.AlertPriceDrop:
+			for i := range current {
+				snap := &current[i]
+				prev, ok := previous[snap.ListingID]
+				if !ok || prev.Price <= 0 || snap.Price <= 0 || !matchesListing(rule, snap) {
+					continue
+				}
+
+				drop := (prev.Price - snap.Price) / prev.Price * 100
+				if drop <= rule.Threshold {
+					continue
+				}
+
+				alert := listingAlert(rule, run, snap, now)
+				alert.Key = fmt.Sprintf("listing:%d:%.2f", snap.ListingID, snap.Price)
+				alert.PreviousPrice = prev.Price
+				alert.Subject = fmt.Sprintf("[%s] Price drop %.0f%%: %s", rule.Name, drop, snap.Title)
+				alert.Message = fmt.Sprintf("%s in %s dropped from $%.2f to $%.2f (-%.1f%%).\nRating: %.2f\n%s",
+					snap.Title, alert.Location, prev.Price, snap.Price, drop, snap.Rating, snap.URL)
+				alerts = append(alerts, alert)
+			}
+
+		case config.AlertNewListing:
+			// New means first stored by this run; listings stored before snapshots or alerts existed
+			// have no earlier snapshot but are not new
+			for i := range current {
+				snap := &current[i]
+				if snap.ListingCreatedAt.Before(run.StartedAt) || !matchesListing(rule, snap) {
+					continue
+				}
+
+				alert := listingAlert(rule, run, snap, now)
+				alert.Key = fmt.Sprintf("listing:%d", snap.ListingID)
+				alert.Subject = fmt.Sprintf("[%s] New listing: %s", rule.Name, snap.Title)
+				alert.Message = fmt.Sprintf("New listing in %s: %s\nPrice: $%.2f | Rating: %.2f | %d guests, %d bedrooms\n%s",
+					alert.Location, snap.Title, snap.Price, snap.Rating, snap.Guests, snap.Bedrooms, snap.URL)
+				alerts = append(alerts, alert)
+			}
+
+		case config.AlertEmptyLocation:
+			locations := make([]string, 0, len(locationCounts))
+			for location, count := range locationCounts {
+				if count == 0 && matchesLocation(rule, location) {
+					locations = append(locations, location)
+				}
+			}
+			sort.Strings(locations)
+
+			for _, location := range locations {
+				alerts = append(alerts, models.Alert{
+					Rule:        rule.Name,
+					Type:        rule.Type,
+					Key:         fmt.Sprintf("location:%s:%s", location, now.Format("2006-01-02")),
+					RunID:       run.ID,
+					Subject:     fmt.Sprintf("[%s] %s returned 0 listings", rule.Name, location),
+					Message:     fmt.Sprintf("Scrape run %d returned no listings for %s.", run.ID, location),
+					Location:    location,
+					TriggeredAt: now,
+				})
+			}
+		}
+	}
+
+	return alerts, nil
+}
+
+// listingAlert fills the listing fields of an alert
+func listingAlert(rule config.AlertRule, run *models.ScrapeRun, snap *models.ListingSnapshot, now time.Time) models.Alert {
+	return models.Alert{
+		Rule:        rule.Name,
+		Type:        rule.Type,
+		RunID:       run.ID,
+		Location:    snapshotLocation(snap),
+		Title:       snap.Title,
+		URL:         snap.URL,
+		Price:       snap.Price,
+		Rating:      snap.Rating,
+		TriggeredAt: now,
+	}
+}
+
+// matchesListing reports whether a snapshot passes the rule's location, price and rating filters
+func matchesListing(rule config.AlertRule, snap *models.ListingSnapshot) bool {
+	if rule.Location != "" && !matchesLocation(rule, snapshotLocation(snap)) && !matchesLocation(rule, snap.Location) {
+		return false
+	}
+	if rule.MaxPrice > 0 && (snap.Price <= 0 || snap.Price > rule.MaxPrice) {
+		return false
+	}
+	if rule.MinRating > 0 && snap.Rating < rule.MinRating {
+		return false
+	}
+	return true
+}
+
+// matchesLocation reports whether a location contains the rule's location text, ignoring case
+func matchesLocation(rule config.AlertRule, location string) bool {
+	return rule.Location == "" || strings.Contains(strings.ToLower(location), strings.ToLower(rule.Location))
+}
+
+// Dispatch delivers the alerts that were not sent before and records them
+// With no channel configured alerts are only logged and not recorded, so they are sent once a channel is added
+// Returns the number of alerts delivered
+func (s *AlertService) Dispatch(alerts []models.Alert) int {
+	delivered := 0
+
+	for i := range alerts {
+		alert := &alerts[i]
+
+		sent, err := s.db.AlertSent(alert.Rule, alert.Key)
+		if err != nil {
+			s.logger.Error("%v", err)
+			continue
+		}
+		if sent {
+			continue
+		}
+
+		s.logger.Warning("🔔 %s", alert.Subject)
+		if len(s.notifiers) == 0 {
+			continue
+		}
+
+		ok := false
+		for _, notifier := range s.notifiers {
+			if err := notifier.Send(alert); err != nil {
+				s.logger.Error("Failed to deliver alert via %s: %v", notifier.Name(), err)
+				continue
+			}
+			ok = true
+		}
+
+		if ok {
+			if err := s.db.RecordAlert(alert); err != nil {
+				s.logger.Error("%v", err)
+			}
+			delivered++
+		}
+	}
+
+	return delivered
+}
+
+// ProcessRun evaluates the rules for a finished run and delivers the new alerts
+func (s *AlertService) ProcessRun(run *models.ScrapeRun, locationCounts map[string]int) error {
+	if len(s.cfg.Rules) == 0 {
+		return nil
+	}
+
+	alerts, err := s.Evaluate(run, locationCounts)
+	if err != nil {
+		return err
+	}
+
+	if len(alerts) == 0 {
+		s.logger.Info("No alert rules triggered")
+		return nil
+	}
+
+	delivered := s.Dispatch(alerts)
+	if len(s.notifiers) == 0 {
+		s.logger.Info("%d alerts triggered; configure alerts.webhook_url or alerts.smtp to deliver them", len(alerts))
+	} else {
+		s.logger.Success("Delivered %d new alerts (%d triggered)", delivered, len(alerts))
+	}
+	return nil
+}
+
+// AlertHealth delivers an alert listing the health checks a run failed
+func (s *AlertService) AlertHealth(run *models.ScrapeRun, issues []string) {
+	alert := models.Alert{
+		Rule:        "scrape-health",
+		Type:        "scrape_health",
+		Key:         fmt.Sprintf("run:%d", run.ID),
+		RunID:       run.ID,
+		Subject:     fmt.Sprintf("[scrape-health] Run %d failed %d health checks", run.ID, len(issues)),
+		Message:     fmt.Sprintf("Scrape run %d (%s) failed health checks:\n- %s", run.ID, run.Kind, strings.Join(issues, "\n- ")),
+		TriggeredAt: time.Now(),
+	}
+
+	s.Dispatch([]models.Alert{alert})
+}
+
+// SendTest sends a sample alert through every configured channel without recording it
+func (s *AlertService) SendTest() error {
+	if len(s.notifiers) == 0 {
+		return fmt.Errorf("no alert channel configured (alerts.webhook_url or alerts.smtp)")
+	}
+
+	alert := &models.Alert{
+		Rule:        "test",
+		Type:        "test",
+		Key:         "test",
+		Subject:     "[test] Airbnb scraper alert test",
+		Message:     "This is a test alert from the Airbnb scraper.",
+		TriggeredAt: time.Now(),
+	}
+
+	failed := 0
+	for _, notifier := range s.notifiers {
+		if err := notifier.Send(alert); err != nil {
+			s.logger.Error("Failed to deliver test alert via %s: %v", notifier.Name(), err)
+			failed++
+			continue
+		}
+		s.logger.Success("Test alert delivered via %s", notifier.Name())
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d channels failed", failed, len(s.notifiers))
+	}
+	return nil
+}
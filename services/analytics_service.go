@@ -2,8 +2,11 @@ package services
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
 	"github.com/farhanasfar/airbnb-market-scraping-system/models"
 	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
 	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
@@ -11,43 +14,53 @@ import (
 
 // AnalyticsService handles analytics and insights
 type AnalyticsService struct {
-	db     *storage.DB
-	logger *utils.Logger
+	db       *storage.DB
+	logger   *utils.Logger
+	cfg      *config.AnalyticsConfig
+	detector *QualityDetector
 }
 
 // Analytics holds all calculated statistics
 type Analytics struct {
-	TotalListings       int
-	AveragePrice        float64
-	MaxPrice            float64
-	MinPrice            float64
-	MostExpensive       *models.Listing
-	ListingsPerLocation map[string]int
-	TopRated            []models.Listing
+	TotalListings           int
+	FlaggedListings         int // left out of the statistics by the data quality detector
+	AveragePrice            float64
+	MaxPrice                float64
+	MinPrice                float64
+	MostExpensive           *models.Listing
+	ListingsPerCity         map[string]int
+	ListingsPerPropertyType map[string]int
+	TopRated                []RankedListing
+	PriceDistribution       *PriceDistribution
+	DistributionPerCity     map[string]*PriceDistribution
 }
 
 // NewAnalyticsService creates a new analytics service
-func NewAnalyticsService(db *storage.DB, logger *utils.Logger) *AnalyticsService {
+func NewAnalyticsService(db *storage.DB, logger *utils.Logger, cfg *config.AnalyticsConfig) *AnalyticsService {
 	return &AnalyticsService{
-		db:     db,
-		logger: logger,
+		db:       db,
+		logger:   logger,
+		cfg:      cfg,
+		detector: NewQualityDetector(cfg),
 	}
 }
 
 // GetAnalytics calculates all analytics from database
 func (s *AnalyticsService) GetAnalytics() (*Analytics, error) {
-	listings, err := s.db.GetAllListings()
+	listings, flagged, err := s.loadListings()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get listings: %w", err)
+		return nil, err
 	}
 
 	if len(listings) == 0 {
-		return &Analytics{}, nil
+		return &Analytics{FlaggedListings: flagged}, nil
 	}
 
 	analytics := &Analytics{
-		TotalListings:       len(listings),
-		ListingsPerLocation: make(map[string]int),
+		TotalListings:           len(listings),
+		FlaggedListings:         flagged,
+		ListingsPerCity:         make(map[string]int),
+		ListingsPerPropertyType: make(map[string]int),
 	}
 
 	// Calculate price statistics
@@ -71,49 +84,216 @@ func (s *AnalyticsService) GetAnalytics() (*Analytics, error) {
 			analytics.MinPrice = listing.Price
 		}
 
-		// Location grouping
-		analytics.ListingsPerLocation[listing.Location]++
+		// City and property type grouping
+		analytics.ListingsPerCity[cityOf(listing)]++
+		analytics.ListingsPerPropertyType[propertyTypeOf(listing)]++
 	}
 
 	analytics.AveragePrice = totalPrice / float64(len(listings))
 
+	// Price distribution, overall and per city
+	analytics.PriceDistribution, analytics.DistributionPerCity = s.priceDistributions(listings)
+
 	// Get top 5 rated properties
-	analytics.TopRated = s.getTopRated(listings, 5)
+	analytics.TopRated = s.getTopRated(listings, 5, s.cfg.MinReviews)
 
 	return analytics, nil
 }
 
-// getTopRated returns top N highest rated listings
-func (s *AnalyticsService) getTopRated(listings []models.Listing, n int) []models.Listing {
-	// Sort by rating (descending)
-	sorted := make([]models.Listing, len(listings))
-	copy(sorted, listings)
+// loadListings retrieves the listings reports are computed from
+// Rows flagged by the data quality detector are left out unless IncludeFlagged is set,
+// and duplicate clusters count once when CountDuplicatesOnce is set
+// Returns the listings and the number of flagged rows left out
+func (s *AnalyticsService) loadListings() ([]models.Listing, int, error) {
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get listings: %w", err)
+	}
+
+	flagged := 0
+	if !s.cfg.IncludeFlagged {
+		var issues []QualityIssue
+		listings, issues = s.detector.Filter(listings)
+		flagged = len(issues)
+	}
+
+	if s.cfg.CountDuplicatesOnce {
+		listings = collapseDuplicates(listings)
+	}
+
+	return listings, flagged, nil
+}
+
+// collapseDuplicates keeps the first listing of each duplicate cluster
+func collapseDuplicates(listings []models.Listing) []models.Listing {
+	seen := make(map[int]bool)
+	kept := make([]models.Listing, 0, len(listings))
+	for _, listing := range listings {
+		if listing.ClusterID != 0 {
+			if seen[listing.ClusterID] {
+				continue
+			}
+			seen[listing.ClusterID] = true
+		}
+		kept = append(kept, listing)
+	}
+	return kept
+}
+
+// priceDistributions computes the price distribution overall and per city
+// Listings without a price are left out so they do not drag the percentiles to zero
+func (s *AnalyticsService) priceDistributions(listings []models.Listing) (*PriceDistribution, map[string]*PriceDistribution) {
+	all := []float64{}
+	byCity := make(map[string][]float64)
+
+	for i := range listings {
+		if listings[i].Price <= 0 {
+			continue
+		}
+		all = append(all, listings[i].Price)
+		city := cityOf(&listings[i])
+		byCity[city] = append(byCity[city], listings[i].Price)
+	}
+
+	perCity := make(map[string]*PriceDistribution, len(byCity))
+	for city, prices := range byCity {
+		perCity[city] = newPriceDistribution(prices, s.cfg.PriceBuckets)
+	}
+
+	return newPriceDistribution(all, s.cfg.PriceBuckets), perCity
+}
+
+// sortedByCount returns the keys of a count map, largest count first, ties alphabetically
+func sortedByCount(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+// cityOf returns the city a listing is grouped under
+// Prefers the canonical gazetteer location, then the parsed city, then the raw location
+func cityOf(listing *models.Listing) string {
+	if listing.CanonicalLocation != "" {
+		return listing.CanonicalLocation
+	}
+	if listing.City != "" {
+		return listing.City
+	}
+	return listing.Location
+}
+
+// propertyTypeOf returns the property type a listing is grouped under
+func propertyTypeOf(listing *models.Listing) string {
+	if listing.PropertyType != "" {
+		return listing.PropertyType
+	}
+	return "Unknown"
+}
+
+// RankedListing pairs a listing with its review-weighted rating
+type RankedListing struct {
+	models.Listing
+	WeightedRating float64
+}
 
-	// Simple bubble sort (fine for small datasets)
-	for i := 0; i < len(sorted)-1; i++ {
-		for j := 0; j < len(sorted)-i-1; j++ {
-			if sorted[j].Rating < sorted[j+1].Rating {
-				sorted[j], sorted[j+1] = sorted[j+1], sorted[j]
+// rankByRating ranks rated listings by their Bayesian average rating:
+//
+//	weighted = (reviews × rating + weight × prior) / (reviews + weight)
+//
+// so a 5.0 with two reviews sits close to the prior while a 4.97 with 800 reviews keeps
+// its rating. The prior is RatingPriorMean, or the mean rating of all rated listings.
+// Listings with fewer than minReviews reviews are left out.
+func (s *AnalyticsService) rankByRating(listings []models.Listing, minReviews int) []RankedListing {
+	prior := s.cfg.RatingPriorMean
+	if prior <= 0 {
+		var total float64
+		var rated int
+		for _, listing := range listings {
+			if listing.Rating > 0 {
+				total += listing.Rating
+				rated++
 			}
 		}
+		if rated > 0 {
+			prior = total / float64(rated)
+		}
 	}
+	weight := s.cfg.RatingPriorWeight
+
+	ranked := []RankedListing{}
+	for _, listing := range listings {
+		if listing.Rating <= 0 || listing.ReviewCount < minReviews {
+			continue
+		}
+
+		reviews := float64(listing.ReviewCount)
+		ranked = append(ranked, RankedListing{
+			Listing:        listing,
+			WeightedRating: (reviews*listing.Rating + weight*prior) / (reviews + weight),
+		})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].WeightedRating != ranked[j].WeightedRating {
+			return ranked[i].WeightedRating > ranked[j].WeightedRating
+		}
+		return ranked[i].ReviewCount > ranked[j].ReviewCount
+	})
+
+	return ranked
+}
+
+// getTopRated returns top N highest rated listings by weighted rating
+func (s *AnalyticsService) getTopRated(listings []models.Listing, n int, minReviews int) []RankedListing {
+	ranked := s.rankByRating(listings, minReviews)
 
 	// Return top N
-	if len(sorted) < n {
-		return sorted
+	if len(ranked) < n {
+		return ranked
+	}
+	return ranked[:n]
+}
+
+// GetTopRatedPerLocation returns the top N weighted-rating listings of each location
+func (s *AnalyticsService) GetTopRatedPerLocation(n int, minReviews int) (map[string][]RankedListing, error) {
+	listings, _, err := s.loadListings()
+	if err != nil {
+		return nil, err
 	}
-	return sorted[:n]
+
+	perLocation := make(map[string][]RankedListing)
+	for _, ranked := range s.rankByRating(listings, minReviews) {
+		location := cityOf(&ranked.Listing)
+		if len(perLocation[location]) < n {
+			perLocation[location] = append(perLocation[location], ranked)
+		}
+	}
+
+	return perLocation, nil
 }
 
 // PrintAnalytics prints all analytics to console
 func (s *AnalyticsService) PrintAnalytics(analytics *Analytics) {
 	// Header
-	s.logger.Info("\n" + strings.Repeat("=", 70))
+	s.logger.Info("\n%s", strings.Repeat("=", 70))
 	s.logger.Info("              AIRBNB SCRAPING ANALYTICS REPORT")
-	s.logger.Info(strings.Repeat("=", 70) + "\n")
+	s.logger.Info("%s\n", strings.Repeat("=", 70))
 
 	// Total listings
 	s.logger.Info("TOTAL LISTINGS: %d\n", analytics.TotalListings)
+	if analytics.FlaggedListings > 0 {
+		s.logger.Warning("%d flagged listings excluded (see --data-quality)\n", analytics.FlaggedListings)
+	}
 
 	// Price statistics
 	s.logger.Info("PRICE STATISTICS:")
@@ -121,6 +301,12 @@ func (s *AnalyticsService) PrintAnalytics(analytics *Analytics) {
 	s.logger.Info("   Maximum Price:        $%.2f", analytics.MaxPrice)
 	s.logger.Info("   Minimum Price:        $%.2f\n", analytics.MinPrice)
 
+	// Price distribution
+	if analytics.PriceDistribution != nil && analytics.PriceDistribution.Count > 0 {
+		s.printDistribution(analytics.PriceDistribution)
+		s.logger.Info("")
+	}
+
 	// Most expensive property
 	if analytics.MostExpensive != nil {
 		s.logger.Info("MOST EXPENSIVE PROPERTY:")
@@ -134,10 +320,22 @@ func (s *AnalyticsService) PrintAnalytics(analytics *Analytics) {
 			analytics.MostExpensive.Guests)
 	}
 
-	// Listings per location
-	s.logger.Info("LISTINGS PER LOCATION:")
-	for location, count := range analytics.ListingsPerLocation {
-		s.logger.Info("   %-35s %d properties", location, count)
+	// Listings per city
+	s.logger.Info("LISTINGS PER CITY:")
+	for _, city := range sortedByCount(analytics.ListingsPerCity) {
+		count := analytics.ListingsPerCity[city]
+		if dist, ok := analytics.DistributionPerCity[city]; ok {
+			s.logger.Info("   %-35s %d properties | median $%.2f | IQR $%.2f", city, count, dist.Median, dist.IQR)
+			continue
+		}
+		s.logger.Info("   %-35s %d properties", city, count)
+	}
+	s.logger.Info("")
+
+	// Listings per property type
+	s.logger.Info("LISTINGS PER PROPERTY TYPE:")
+	for _, propertyType := range sortedByCount(analytics.ListingsPerPropertyType) {
+		s.logger.Info("   %-35s %d properties", propertyType, analytics.ListingsPerPropertyType[propertyType])
 	}
 	s.logger.Info("")
 
@@ -145,12 +343,77 @@ func (s *AnalyticsService) PrintAnalytics(analytics *Analytics) {
 	s.logger.Info("⭐ TOP 5 HIGHEST RATED PROPERTIES:")
 	for i, listing := range analytics.TopRated {
 		s.logger.Info("\n   %d. %s", i+1, listing.Title)
-		s.logger.Info("      Rating: %.2f ⭐ | Price: $%.2f | Location: %s",
-			listing.Rating, listing.Price, listing.Location)
+		s.logger.Info("      Rating: %.2f ⭐ (%d reviews, weighted %.2f) | Price: $%.2f | Location: %s",
+			listing.Rating, listing.ReviewCount, listing.WeightedRating, listing.Price, cityOf(&listing.Listing))
 	}
 
 	// Footer
-	s.logger.Info("\n" + strings.Repeat("=", 70) + "\n")
+	s.logger.Info("\n%s\n", strings.Repeat("=", 70))
+}
+
+// printDistribution prints percentiles, spread and histogram of a price distribution
+func (s *AnalyticsService) printDistribution(dist *PriceDistribution) {
+	s.logger.Info("PRICE DISTRIBUTION (%d priced listings):", dist.Count)
+	s.logger.Info("   Median:               $%.2f", dist.Median)
+	s.logger.Info("   P10 / P25:            $%.2f / $%.2f", dist.P10, dist.P25)
+	s.logger.Info("   P75 / P90:            $%.2f / $%.2f", dist.P75, dist.P90)
+	s.logger.Info("   IQR:                  $%.2f", dist.IQR)
+	s.logger.Info("   Std Deviation:        $%.2f", dist.StdDev)
+
+	s.logger.Info("   Histogram:")
+	for _, bucket := range dist.Histogram {
+		s.logger.Info("   %-20s %5d %s", bucketLabel(bucket), bucket.Count, histogramBar(bucket.Count, dist.Count))
+	}
+}
+
+// bucketLabel formats a histogram bucket like "$100 - $150" or "$1000+"
+func bucketLabel(bucket HistogramBucket) string {
+	if math.IsInf(bucket.High, 1) {
+		return fmt.Sprintf("$%.0f+", bucket.Low)
+	}
+	return fmt.Sprintf("$%.0f - $%.0f", bucket.Low, bucket.High)
+}
+
+// histogramBar draws a bar proportional to count out of total
+func histogramBar(count, total int) string {
+	if total == 0 {
+		return ""
+	}
+	return strings.Repeat("█", count*40/total)
+}
+
+// PrintPriceDistribution prints the price distribution overall and per location
+func (s *AnalyticsService) PrintPriceDistribution() error {
+	analytics, err := s.GetAnalytics()
+	if err != nil {
+		return err
+	}
+
+	if analytics.PriceDistribution == nil || analytics.PriceDistribution.Count == 0 {
+		s.logger.Info("\nNo priced listings yet\n")
+		return nil
+	}
+
+	s.logger.Info("")
+	s.printDistribution(analytics.PriceDistribution)
+
+	s.logger.Info("\nPRICE DISTRIBUTION BY LOCATION:")
+	s.logger.Info("   %-35s %5s %9s %9s %9s %9s %9s %9s %9s",
+		"Location", "Count", "P10", "P25", "Median", "P75", "P90", "IQR", "StdDev")
+
+	cities := make([]string, 0, len(analytics.DistributionPerCity))
+	for city := range analytics.DistributionPerCity {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+
+	for _, city := range cities {
+		dist := analytics.DistributionPerCity[city]
+		s.logger.Info("   %-35s %5d %9.2f %9.2f %9.2f %9.2f %9.2f %9.2f %9.2f",
+			city, dist.Count, dist.P10, dist.P25, dist.Median, dist.P75, dist.P90, dist.IQR, dist.StdDev)
+	}
+	s.logger.Info("")
+	return nil
 }
 
 // PrintAveragePrice prints only average price
@@ -181,19 +444,50 @@ func (s *AnalyticsService) PrintMaxPrice() error {
 	return nil
 }
 
-// PrintTopRated prints top 5 rated properties
-func (s *AnalyticsService) PrintTopRated() error {
-	analytics, err := s.GetAnalytics()
+// PrintTopRated prints the top n properties by review-weighted rating
+// minReviews < 0 uses the configured minimum; perLocation ranks each location separately
+func (s *AnalyticsService) PrintTopRated(n int, minReviews int, perLocation bool) error {
+	if minReviews < 0 {
+		minReviews = s.cfg.MinReviews
+	}
+
+	if perLocation {
+		ranked, err := s.GetTopRatedPerLocation(n, minReviews)
+		if err != nil {
+			return err
+		}
+
+		locations := make([]string, 0, len(ranked))
+		for location := range ranked {
+			locations = append(locations, location)
+		}
+		sort.Strings(locations)
+
+		s.logger.Info("\n⭐ TOP %d HIGHEST RATED PROPERTIES PER LOCATION (min %d reviews):", n, minReviews)
+		for _, location := range locations {
+			s.logger.Info("\n   %s", location)
+			for i, listing := range ranked[location] {
+				s.logger.Info("   %d. %s", i+1, listing.Title)
+				s.logger.Info("      Rating: %.2f ⭐ (%d reviews, weighted %.2f) | Price: $%.2f",
+					listing.Rating, listing.ReviewCount, listing.WeightedRating, listing.Price)
+			}
+		}
+		s.logger.Info("")
+		return nil
+	}
+
+	listings, _, err := s.loadListings()
 	if err != nil {
 		return err
 	}
 
-	s.logger.Info("\n⭐ TOP 5 HIGHEST RATED PROPERTIES:")
-	for i, listing := range analytics.TopRated {
+	s.logger.Info("\n⭐ TOP %d HIGHEST RATED PROPERTIES (min %d reviews):", n, minReviews)
+	for i, listing := range s.getTopRated(listings, n, minReviews) {
 		s.logger.Info("\n   %d. %s", i+1, listing.Title)
-		s.logger.Info("      Rating:    %.2f ⭐", listing.Rating)
+		s.logger.Info("      Rating:    %.2f ⭐ (%d reviews, weighted %.2f)",
+			listing.Rating, listing.ReviewCount, listing.WeightedRating)
 		s.logger.Info("      Price:     $%.2f per night", listing.Price)
-		s.logger.Info("      Location:  %s", listing.Location)
+		s.logger.Info("      Location:  %s", cityOf(&listing.Listing))
 		s.logger.Info("      Bedrooms: %d | Bathrooms: %d | Guests: %d",
 			listing.Bedrooms, listing.Bathrooms, listing.Guests)
 	}
@@ -201,16 +495,21 @@ func (s *AnalyticsService) PrintTopRated() error {
 	return nil
 }
 
-// PrintByLocation prints listings grouped by location
+// PrintByLocation prints listings grouped by city and by property type
 func (s *AnalyticsService) PrintByLocation() error {
 	analytics, err := s.GetAnalytics()
 	if err != nil {
 		return err
 	}
 
-	s.logger.Info("\n LISTINGS BY LOCATION:")
-	for location, count := range analytics.ListingsPerLocation {
-		s.logger.Info("   %-35s %d properties", location, count)
+	s.logger.Info("\n LISTINGS BY CITY:")
+	for _, city := range sortedByCount(analytics.ListingsPerCity) {
+		s.logger.Info("   %-35s %d properties", city, analytics.ListingsPerCity[city])
+	}
+
+	s.logger.Info("\n LISTINGS BY PROPERTY TYPE:")
+	for _, propertyType := range sortedByCount(analytics.ListingsPerPropertyType) {
+		s.logger.Info("   %-35s %d properties", propertyType, analytics.ListingsPerPropertyType[propertyType])
 	}
 	s.logger.Info("")
 	return nil
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// CompsService finds the listings most comparable to a target listing and benchmarks it against them
+type CompsService struct {
+	db       *storage.DB
+	logger   *utils.Logger
+	cfg      *config.CompsConfig
+	detector *QualityDetector
+}
+
+// Comp is a comparable listing and how far it is from the target
+type Comp struct {
+	Listing    models.Listing
+	DistanceKm float64 // -1 when either listing has no coordinates
+	Score      float64 // dissimilarity, 0 = identical on every criterion
+	Value      float64 // value score within the comparable set
+}
+
+// CompSet is a target listing, its comparable listings and where the target ranks among them
+type CompSet struct {
+	Target           models.Listing
+	TargetValue      float64
+	Comps            []Comp
+	PricePercentile  float64 // share of comps priced below the target, percent
+	RatingPercentile float64 // share of comps rated below the target, percent; NaN when unrated
+	ValuePercentile  float64 // share of comps with a lower value score, percent; NaN without capacity
+	PriceP25         float64
+	PriceMedian      float64
+	PriceP75         float64
+}
+
+// NewCompsService creates a new comps service
+// Listings flagged by the data quality checks are never used as comps
+func NewCompsService(db *storage.DB, logger *utils.Logger, cfg *config.CompsConfig, analyticsCfg *config.AnalyticsConfig) *CompsService {
+	return &CompsService{
+		db:       db,
+		logger:   logger,
+		cfg:      cfg,
+		detector: NewQualityDetector(analyticsCfg),
+	}
+}
+
+// FindComps builds the comparable set of the listing with the given room ID or URL
+func (s *CompsService) FindComps(ref string) (*CompSet, error) {
+	id := roomIDOf(ref)
+	if id == "" {
+		return nil, fmt.Errorf("%q is neither a listing URL nor a room ID", ref)
+	}
+
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+
+	var target *models.Listing
+	for i := range listings {
+		if listings[i].ListingID == id {
+			target = &listings[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("listing %s is not stored yet; scrape it first", id)
+	}
+
+	candidates, _ := s.detector.Filter(listings)
+	comps := []Comp{}
+	for _, candidate := range candidates {
+		if candidate.ID == target.ID || candidate.Price <= 0 {
+			continue
+		}
+		if target.ClusterID != 0 && candidate.ClusterID == target.ClusterID {
+			continue // a probable duplicate of the target is not a comp
+		}
+		if comp, ok := s.compare(target, &candidate); ok {
+			comps = append(comps, comp)
+		}
+	}
+
+	sort.SliceStable(comps, func(i, j int) bool { return comps[i].Score < comps[j].Score })
+	if len(comps) > s.cfg.Count {
+		comps = comps[:s.cfg.Count]
+	}
+
+	return benchmark(*target, comps), nil
+}
+
+// compare scores how different a candidate is from the target and reports whether it is in range
+// Candidates must lie within MaxDistanceKm when both have coordinates, or share the target's location.
+// The score adds up:
+//   - distance / MaxDistanceKm (0.5 when coordinates are missing)
+//   - guest difference relative to the target's guests (0.5 when either is unknown)
+//   - bedroom difference relative to the target's bedrooms, studios counting as one (0.5 when either is unknown)
+//   - 1 for a different property type
+//   - rating difference / 0.5 (0.5 when either is unrated)
+func (s *CompsService) compare(target, candidate *models.Listing) (Comp, bool) {
+	comp := Comp{Listing: *candidate, DistanceKm: -1}
+
+	if target.Latitude != 0 && target.Longitude != 0 && candidate.Latitude != 0 && candidate.Longitude != 0 {
+		comp.DistanceKm = haversineMeters(target.Latitude, target.Longitude, candidate.Latitude, candidate.Longitude) / 1000
+		if comp.DistanceKm > s.cfg.MaxDistanceKm {
+			return comp, false
+		}
+		comp.Score += comp.DistanceKm / s.cfg.MaxDistanceKm
+	} else {
+		if cityOf(target) != cityOf(candidate) {
+			return comp, false
+		}
+		comp.Score += 0.5
+	}
+
+	if target.Guests > 0 && candidate.Guests > 0 {
+		comp.Score += math.Abs(float64(candidate.Guests-target.Guests)) / float64(target.Guests)
+	} else {
+		comp.Score += 0.5
+	}
+	if targetRooms, candidateRooms := bedroomsOf(target), bedroomsOf(candidate); targetRooms > 0 && candidateRooms > 0 {
+		comp.Score += math.Abs(float64(candidateRooms-targetRooms)) / float64(targetRooms)
+	} else {
+		comp.Score += 0.5
+	}
+	if propertyTypeOf(candidate) != propertyTypeOf(target) {
+		comp.Score++
+	}
+	if target.Rating > 0 && candidate.Rating > 0 {
+		comp.Score += math.Abs(candidate.Rating-target.Rating) / 0.5
+	} else {
+		comp.Score += 0.5
+	}
+
+	return comp, true
+}
+
+// bedroomsOf returns the bedrooms of a listing with studios counted as one, 0 when unknown
+func bedroomsOf(l *models.Listing) int {
+	if l.Studio {
+		return 1
+	}
+	return l.Bedrooms
+}
+
+// benchmark ranks the target's price, rating and value within its comparable set
+// Value follows the value score of --best-value, measured against the comp set instead of the location
+func benchmark(target models.Listing, comps []Comp) *CompSet {
+	set := &CompSet{Target: target, Comps: comps}
+
+	var prices, ratings, perGuest []float64
+	for _, comp := range comps {
+		prices = append(prices, comp.Listing.Price)
+		if comp.Listing.Rating > 0 {
+			ratings = append(ratings, comp.Listing.Rating)
+		}
+		if comp.Listing.Guests > 0 {
+			perGuest = append(perGuest, comp.Listing.Price/float64(comp.Listing.Guests))
+		}
+	}
+	sort.Float64s(prices)
+	sort.Float64s(perGuest)
+	set.PriceP25 = percentile(prices, 25)
+	set.PriceMedian = percentile(prices, 50)
+	set.PriceP75 = percentile(prices, 75)
+
+	meanRating := mean(ratings)
+	medianPerGuest := percentile(perGuest, 50)
+	value := func(l *models.Listing) float64 {
+		if l.Guests <= 0 || l.Price <= 0 || medianPerGuest == 0 {
+			return math.NaN()
+		}
+		ratingFactor := 1.0
+		if l.Rating > 0 && meanRating > 0 {
+			ratingFactor = l.Rating / meanRating
+		}
+		return 100 * ratingFactor * medianPerGuest / (l.Price / float64(l.Guests))
+	}
+
+	values := []float64{}
+	for i := range set.Comps {
+		set.Comps[i].Value = value(&set.Comps[i].Listing)
+		if !math.IsNaN(set.Comps[i].Value) {
+			values = append(values, set.Comps[i].Value)
+		}
+	}
+	set.TargetValue = value(&target)
+
+	set.PricePercentile = percentileRank(prices, target.Price)
+	set.RatingPercentile = math.NaN()
+	if target.Rating > 0 {
+		set.RatingPercentile = percentileRank(ratings, target.Rating)
+	}
+	set.ValuePercentile = math.NaN()
+	if !math.IsNaN(set.TargetValue) {
+		set.ValuePercentile = percentileRank(values, set.TargetValue)
+	}
+
+	return set
+}
+
+// percentileRank returns the share of values below v, counting ties as half, in percent
+// Returns NaN for no values
+func percentileRank(values []float64, v float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	var below float64
+	for _, x := range values {
+		switch {
+		case x < v:
+			below++
+		case x == v:
+			below += 0.5
+		}
+	}
+	return below / float64(len(values)) * 100
+}
+
+// PrintComps prints the comparable set of a listing and the target's percentiles within it
+func (s *CompsService) PrintComps(ref string) error {
+	set, err := s.FindComps(ref)
+	if err != nil {
+		return err
+	}
+
+	t := set.Target
+	s.logger.Info("\n🏘️  COMPARABLE SET: %s", t.Title)
+	s.logger.Info("   %s | $%.2f | %d guests, %d bedrooms | %s | %.2f ⭐ (%d reviews)",
+		cityOf(&t), t.Price, t.Guests, t.Bedrooms, propertyTypeOf(&t), t.Rating, t.ReviewCount)
+
+	if len(set.Comps) == 0 {
+		s.logger.Info("   No comparable listings found nearby\n")
+		return nil
+	}
+
+	s.logger.Info("\n   Target percentile within %d comps (0 = lowest, 100 = highest):", len(set.Comps))
+	s.logger.Info("   Price:   %s", formatRank(set.PricePercentile))
+	s.logger.Info("   Rating:  %s", formatRank(set.RatingPercentile))
+	s.logger.Info("   Value:   %s (score %s)", formatRank(set.ValuePercentile), formatScore(set.TargetValue))
+	s.logger.Info("   Comp prices: P25 $%.2f | median $%.2f | P75 $%.2f", set.PriceP25, set.PriceMedian, set.PriceP75)
+
+	s.logger.Info("\n   %-40s %10s %6s %8s %-12s %6s %8s %6s", "Comp", "Price", "Guests", "Bedrooms", "Type", "Rating", "Distance", "Value")
+	s.logger.Info("   %s", strings.Repeat("-", 104))
+	for _, comp := range set.Comps {
+		l := comp.Listing
+		distance := "-"
+		if comp.DistanceKm >= 0 {
+			distance = fmt.Sprintf("%.1f km", comp.DistanceKm)
+		}
+		s.logger.Info("   %-40s %10.2f %6d %8d %-12s %6.2f %8s %6s",
+			truncate(l.Title, 40), l.Price, l.Guests, l.Bedrooms, truncate(propertyTypeOf(&l), 12), l.Rating, distance, formatScore(comp.Value))
+	}
+	s.logger.Info("")
+	return nil
+}
+
+// formatRank formats a percentile rank as P0-P100, "-" when unknown
+func formatRank(rank float64) string {
+	if math.IsNaN(rank) {
+		return "-"
+	}
+	return fmt.Sprintf("P%.0f", rank)
+}
+
+// formatScore formats a value score, "-" when unknown
+func formatScore(score float64) string {
+	if math.IsNaN(score) {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", score)
+}
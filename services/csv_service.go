@@ -52,13 +52,23 @@ func (s *CSVService) ExportToCSV(filename string) error {
 	// Write header
 	header := []string{
 		"ID",
+		"Listing ID",
 		"Title",
 		"Price",
 		"Location",
+		"Property Type",
+		"City",
 		"Rating",
+		"Reviews",
 		"Bedrooms",
+		"Beds",
 		"Bathrooms",
+		"Half Baths",
+		"Bathroom Type",
+		"Studio",
 		"Guests",
+		"Amenities",
+		"Segment",
 		"URL",
 		"Created At",
 	}
@@ -71,13 +81,23 @@ func (s *CSVService) ExportToCSV(filename string) error {
 	for _, listing := range listings {
 		row := []string{
 			fmt.Sprintf("%d", listing.ID),
+			listing.ListingID,
 			listing.Title,
 			fmt.Sprintf("%.2f", listing.Price),
 			listing.Location,
+			listing.PropertyType,
+			listing.City,
 			fmt.Sprintf("%.2f", listing.Rating),
+			fmt.Sprintf("%d", listing.ReviewCount),
 			fmt.Sprintf("%d", listing.Bedrooms),
+			fmt.Sprintf("%d", listing.Beds),
 			fmt.Sprintf("%d", listing.Bathrooms),
+			fmt.Sprintf("%d", listing.HalfBaths),
+			listing.BathroomType,
+			fmt.Sprintf("%t", listing.Studio),
 			fmt.Sprintf("%d", listing.Guests),
+			fmt.Sprintf("%d", listing.Amenities),
+			listing.Segment,
 			listing.URL,
 			listing.CreatedAt.Format("2006-01-02 15:04:05"),
 		}
@@ -115,10 +135,19 @@ func (s *CSVService) ExportListingsToCSV(listings []models.Listing, filename str
 		"Title",
 		"Price",
 		"Location",
+		"Property Type",
+		"City",
 		"Rating",
+		"Reviews",
 		"Bedrooms",
+		"Beds",
 		"Bathrooms",
+		"Half Baths",
+		"Bathroom Type",
+		"Studio",
 		"Guests",
+		"Amenities",
+		"Segment",
 		"URL",
 	}
 
@@ -132,10 +161,19 @@ func (s *CSVService) ExportListingsToCSV(listings []models.Listing, filename str
 			listing.Title,
 			fmt.Sprintf("%.2f", listing.Price),
 			listing.Location,
+			listing.PropertyType,
+			listing.City,
 			fmt.Sprintf("%.2f", listing.Rating),
+			fmt.Sprintf("%d", listing.ReviewCount),
 			fmt.Sprintf("%d", listing.Bedrooms),
+			fmt.Sprintf("%d", listing.Beds),
 			fmt.Sprintf("%d", listing.Bathrooms),
+			fmt.Sprintf("%d", listing.HalfBaths),
+			listing.BathroomType,
+			fmt.Sprintf("%t", listing.Studio),
 			fmt.Sprintf("%d", listing.Guests),
+			fmt.Sprintf("%d", listing.Amenities),
+			listing.Segment,
 			listing.URL,
 		}
 
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// Outlier detection methods
+const (
+	OutlierIQR = "iqr" // Tukey fences: outside [P25 - k×IQR, P75 + k×IQR]
+	OutlierMAD = "mad" // robust z-score: |0.6745 × (x - median) / MAD| > k
+)
+
+// minOutlierGroup is the smallest location sample price outliers are computed for
+const minOutlierGroup = 4
+
+// QualityIssue is a listing flagged by the data quality detector
+type QualityIssue struct {
+	Listing models.Listing
+	Reasons []string
+}
+
+// QualityDetector flags price outliers and suspicious records
+type QualityDetector struct {
+	method    string
+	threshold float64
+}
+
+// NewQualityDetector creates a detector using the configured outlier method
+func NewQualityDetector(cfg *config.AnalyticsConfig) *QualityDetector {
+	return &QualityDetector{
+		method:    cfg.OutlierMethod,
+		threshold: cfg.OutlierThreshold,
+	}
+}
+
+// Detect returns every flagged listing with the reasons it was flagged
+func (d *QualityDetector) Detect(listings []models.Listing) []QualityIssue {
+	reasons := make(map[int][]string)
+
+	for i := range listings {
+		listing := &listings[i]
+
+		if listing.Price <= 0 {
+			reasons[i] = append(reasons[i], "price is 0")
+		}
+		if listing.Rating <= 0 && listing.ReviewCount > 0 {
+			reasons[i] = append(reasons[i], fmt.Sprintf("rating is 0 with %d reviews", listing.ReviewCount))
+		}
+		if listing.Title != "" && listing.Title == listing.Location {
+			reasons[i] = append(reasons[i], "title identical to location")
+		}
+		if listing.Guests > 0 && listing.Guests < listing.Bedrooms {
+			reasons[i] = append(reasons[i], fmt.Sprintf("%d guests for %d bedrooms", listing.Guests, listing.Bedrooms))
+		}
+	}
+
+	for i, reason := range d.priceOutliers(listings) {
+		reasons[i] = append(reasons[i], reason)
+	}
+
+	indexes := make([]int, 0, len(reasons))
+	for i := range reasons {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	issues := make([]QualityIssue, 0, len(indexes))
+	for _, i := range indexes {
+		issues = append(issues, QualityIssue{Listing: listings[i], Reasons: reasons[i]})
+	}
+
+	return issues
+}
+
+// Filter splits listings into clean ones and flagged ones
+func (d *QualityDetector) Filter(listings []models.Listing) ([]models.Listing, []QualityIssue) {
+	issues := d.Detect(listings)
+
+	flagged := make(map[int]bool, len(issues))
+	for _, issue := range issues {
+		flagged[issue.Listing.ID] = true
+	}
+
+	clean := make([]models.Listing, 0, len(listings)-len(issues))
+	for _, listing := range listings {
+		if !flagged[listing.ID] {
+			clean = append(clean, listing)
+		}
+	}
+
+	return clean, issues
+}
+
+// priceOutliers flags listings whose price is an outlier within their location
+// Returns listing index -> reason
+func (d *QualityDetector) priceOutliers(listings []models.Listing) map[int]string {
+	groups := make(map[string][]int)
+	for i := range listings {
+		if listings[i].Price > 0 {
+			location := cityOf(&listings[i])
+			groups[location] = append(groups[location], i)
+		}
+	}
+
+	outliers := make(map[int]string)
+	for location, indexes := range groups {
+		if len(indexes) < minOutlierGroup {
+			continue
+		}
+
+		prices := make([]float64, len(indexes))
+		for j, i := range indexes {
+			prices[j] = listings[i].Price
+		}
+		sort.Float64s(prices)
+
+		switch d.method {
+		case OutlierMAD:
+			med := percentile(prices, 50)
+			deviations := make([]float64, len(prices))
+			for j, price := range prices {
+				deviations[j] = math.Abs(price - med)
+			}
+			sort.Float64s(deviations)
+			mad := percentile(deviations, 50)
+			if mad == 0 {
+				continue
+			}
+
+			for _, i := range indexes {
+				z := 0.6745 * (listings[i].Price - med) / mad
+				if math.Abs(z) > d.threshold {
+					outliers[i] = fmt.Sprintf("price outlier in %s (robust z-score %.1f)", location, z)
+				}
+			}
+
+		default:
+			p25, p75 := percentile(prices, 25), percentile(prices, 75)
+			iqr := p75 - p25
+			low, high := p25-d.threshold*iqr, p75+d.threshold*iqr
+
+			for _, i := range indexes {
+				price := listings[i].Price
+				if price < low || price > high {
+					outliers[i] = fmt.Sprintf("price outlier in %s ($%.2f outside $%.2f - $%.2f)", location, price, low, high)
+				}
+			}
+		}
+	}
+
+	return outliers
+}
+
+// PrintDataQuality lists every flagged listing with the reasons it was flagged
+func (s *AnalyticsService) PrintDataQuality() error {
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return fmt.Errorf("failed to get listings: %w", err)
+	}
+
+	issues := s.detector.Detect(listings)
+
+	s.logger.Info("\n DATA QUALITY REPORT (%s outliers, threshold %.1f):", s.cfg.OutlierMethod, s.cfg.OutlierThreshold)
+	s.logger.Info("   Listings checked:     %d", len(listings))
+	s.logger.Info("   Listings flagged:     %d", len(issues))
+
+	if len(issues) == 0 {
+		s.logger.Info("")
+		return nil
+	}
+
+	for i, issue := range issues {
+		s.logger.Info("\n   %d. %s", i+1, issue.Listing.Title)
+		s.logger.Info("      Price: $%.2f | Rating: %.2f (%d reviews) | Location: %s",
+			issue.Listing.Price, issue.Listing.Rating, issue.Listing.ReviewCount, cityOf(&issue.Listing))
+		s.logger.Info("      URL: %s", issue.Listing.URL)
+		for _, reason := range issue.Reasons {
+			s.logger.Warning("      %s", reason)
+		}
+	}
+
+	if !s.cfg.IncludeFlagged {
+		s.logger.Info("\n   Flagged listings are excluded from analytics; pass --include-flagged to keep them")
+	}
+	s.logger.Info("")
+	return nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// DiffService compares the listings captured by two scrape runs or on two dates
+type DiffService struct {
+	db     *storage.DB
+	logger *utils.Logger
+}
+
+// DiffListing is a listing as captured on one side of a diff
+type DiffListing struct {
+	Title     string  `json:"title"`
+	URL       string  `json:"url"`
+	Price     float64 `json:"price"`
+	Rating    float64 `json:"rating"`
+	Bedrooms  int     `json:"bedrooms"`
+	Bathrooms int     `json:"bathrooms"`
+	Guests    int     `json:"guests"`
+}
+
+// ValueChange is a field of a listing that differs between the two sides
+type ValueChange struct {
+	Title     string  `json:"title"`
+	URL       string  `json:"url"`
+	Field     string  `json:"field,omitempty"` // bedrooms, bathrooms or guests for capacity corrections
+	From      float64 `json:"from"`
+	To        float64 `json:"to"`
+	Change    float64 `json:"change"`
+	ChangePct float64 `json:"change_pct"`
+}
+
+// LocationDiff holds the differences within one location
+type LocationDiff struct {
+	Location          string        `json:"location"`
+	New               []DiffListing `json:"new"`
+	Removed           []DiffListing `json:"removed"`
+	PriceChanges      []ValueChange `json:"price_changes"`
+	RatingChanges     []ValueChange `json:"rating_changes"`
+	CapacityChanges   []ValueChange `json:"capacity_corrections"`
+	UnchangedListings int           `json:"unchanged"`
+}
+
+// RunDiff is the full comparison of two scrape runs or dates
+type RunDiff struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Locations []LocationDiff `json:"locations"`
+}
+
+// NewDiffService creates a new diff service
+func NewDiffService(db *storage.DB, logger *utils.Logger) *DiffService {
+	return &DiffService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// loadSide resolves a run id or a YYYY-MM-DD date to the snapshots of that side, keyed by listing
+// A date covers every snapshot captured that day; each listing keeps its last one
+func (s *DiffService) loadSide(target string) (string, map[int]*models.ListingSnapshot, error) {
+	var snapshots []models.ListingSnapshot
+	var label string
+
+	if runID, err := strconv.Atoi(target); err == nil {
+		run, err := s.db.GetScrapeRun(runID)
+		if err != nil {
+			return "", nil, err
+		}
+		snapshots, err = s.db.GetSnapshotsForRun(run.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		label = fmt.Sprintf("run %d (%s, %s)", run.ID, run.Kind, run.StartedAt.Format("2006-01-02 15:04"))
+	} else {
+		day, err := time.Parse("2006-01-02", target)
+		if err != nil {
+			return "", nil, fmt.Errorf("%q is neither a run id nor a YYYY-MM-DD date", target)
+		}
+		snapshots, err = s.db.GetSnapshotsBetween(day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return "", nil, err
+		}
+		label = day.Format("2006-01-02")
+	}
+
+	byListing := make(map[int]*models.ListingSnapshot, len(snapshots))
+	for i := range snapshots {
+		byListing[snapshots[i].ListingID] = &snapshots[i]
+	}
+
+	return label, byListing, nil
+}
+
+// Compare diffs two sides given as run ids or YYYY-MM-DD dates
+func (s *DiffService) Compare(from, to string) (*RunDiff, error) {
+	fromLabel, before, err := s.loadSide(from)
+	if err != nil {
+		return nil, err
+	}
+	toLabel, after, err := s.loadSide(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffSnapshots(before, after)
+	diff.From = fromLabel
+	diff.To = toLabel
+	return diff, nil
+}
+
+// diffSnapshots compares two sides keyed by listing and groups the differences by location.
+// Zero prices and capacities mean the value was not captured and are not reported as changes.
+func diffSnapshots(before, after map[int]*models.ListingSnapshot) *RunDiff {
+	groups := make(map[string]*LocationDiff)
+	group := func(snap *models.ListingSnapshot) *LocationDiff {
+		location := snapshotLocation(snap)
+		if groups[location] == nil {
+			groups[location] = &LocationDiff{
+				Location:        location,
+				New:             []DiffListing{},
+				Removed:         []DiffListing{},
+				PriceChanges:    []ValueChange{},
+				RatingChanges:   []ValueChange{},
+				CapacityChanges: []ValueChange{},
+			}
+		}
+		return groups[location]
+	}
+
+	for id, old := range before {
+		if _, ok := after[id]; !ok {
+			g := group(old)
+			g.Removed = append(g.Removed, diffListing(old))
+		}
+	}
+
+	for id, cur := range after {
+		g := group(cur)
+		old, ok := before[id]
+		if !ok {
+			g.New = append(g.New, diffListing(cur))
+			continue
+		}
+
+		changed := false
+		if old.Price > 0 && cur.Price > 0 && old.Price != cur.Price {
+			g.PriceChanges = append(g.PriceChanges, valueChange(cur, "", old.Price, cur.Price))
+			changed = true
+		}
+		if old.Rating > 0 && cur.Rating > 0 && old.Rating != cur.Rating {
+			g.RatingChanges = append(g.RatingChanges, valueChange(cur, "", old.Rating, cur.Rating))
+			changed = true
+		}
+
+		capacity := []struct {
+			field    string
+			old, cur int
+		}{
+			{"bedrooms", old.Bedrooms, cur.Bedrooms},
+			{"bathrooms", old.Bathrooms, cur.Bathrooms},
+			{"guests", old.Guests, cur.Guests},
+		}
+		for _, c := range capacity {
+			if c.old > 0 && c.cur > 0 && c.old != c.cur {
+				g.CapacityChanges = append(g.CapacityChanges, valueChange(cur, c.field, float64(c.old), float64(c.cur)))
+				changed = true
+			}
+		}
+
+		if !changed {
+			g.UnchangedListings++
+		}
+	}
+
+	diff := &RunDiff{Locations: make([]LocationDiff, 0, len(groups))}
+	for _, g := range groups {
+		sort.Slice(g.New, func(i, j int) bool { return g.New[i].Title < g.New[j].Title })
+		sort.Slice(g.Removed, func(i, j int) bool { return g.Removed[i].Title < g.Removed[j].Title })
+		sortByChange(g.PriceChanges)
+		sortByChange(g.RatingChanges)
+		sortByChange(g.CapacityChanges)
+		diff.Locations = append(diff.Locations, *g)
+	}
+	sort.Slice(diff.Locations, func(i, j int) bool { return diff.Locations[i].Location < diff.Locations[j].Location })
+
+	return diff
+}
+
+// diffListing converts a snapshot to its diff representation
+func diffListing(snap *models.ListingSnapshot) DiffListing {
+	return DiffListing{
+		Title:     snap.Title,
+		URL:       snap.URL,
+		Price:     snap.Price,
+		Rating:    snap.Rating,
+		Bedrooms:  snap.Bedrooms,
+		Bathrooms: snap.Bathrooms,
+		Guests:    snap.Guests,
+	}
+}
+
+// valueChange describes a field that changed from one value to another
+func valueChange(snap *models.ListingSnapshot, field string, from, to float64) ValueChange {
+	return ValueChange{
+		Title:     snap.Title,
+		URL:       snap.URL,
+		Field:     field,
+		From:      from,
+		To:        to,
+		Change:    to - from,
+		ChangePct: percentChange(from, to),
+	}
+}
+
+// sortByChange orders changes by absolute relative change, largest first
+func sortByChange(changes []ValueChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return math.Abs(changes[i].ChangePct) > math.Abs(changes[j].ChangePct)
+	})
+}
+
+// PrintDiff prints the comparison of two runs or dates as tables per location
+func (s *DiffService) PrintDiff(from, to string) error {
+	diff, err := s.Compare(from, to)
+	if err != nil {
+		return err
+	}
+
+	var added, removed, prices, ratings, capacity int
+	for _, g := range diff.Locations {
+		added += len(g.New)
+		removed += len(g.Removed)
+		prices += len(g.PriceChanges)
+		ratings += len(g.RatingChanges)
+		capacity += len(g.CapacityChanges)
+	}
+
+	s.logger.Info("\n🔍 DIFF: %s → %s", diff.From, diff.To)
+	s.logger.Info("   New listings:          %d", added)
+	s.logger.Info("   Removed listings:      %d", removed)
+	s.logger.Info("   Price changes:         %d", prices)
+	s.logger.Info("   Rating changes:        %d", ratings)
+	s.logger.Info("   Capacity corrections:  %d", capacity)
+
+	for _, g := range diff.Locations {
+		if len(g.New)+len(g.Removed)+len(g.PriceChanges)+len(g.RatingChanges)+len(g.CapacityChanges) == 0 {
+			continue
+		}
+
+		s.logger.Info("\n   %s", g.Location)
+		s.logger.Info("   %s", strings.Repeat("-", 90))
+		for _, l := range g.New {
+			s.logger.Info("   + %-50s $%9.2f  %.2f ⭐", truncate(l.Title, 50), l.Price, l.Rating)
+		}
+		for _, l := range g.Removed {
+			s.logger.Info("   - %-50s $%9.2f  %.2f ⭐", truncate(l.Title, 50), l.Price, l.Rating)
+		}
+		for _, c := range g.PriceChanges {
+			s.logger.Info("   $ %-50s $%9.2f → $%9.2f (%+.2f, %s)",
+				truncate(c.Title, 50), c.From, c.To, c.Change, formatPercent(c.ChangePct))
+		}
+		for _, c := range g.RatingChanges {
+			s.logger.Info("   ⭐ %-49s %.2f → %.2f (%+.2f)", truncate(c.Title, 49), c.From, c.To, c.Change)
+		}
+		for _, c := range g.CapacityChanges {
+			s.logger.Info("   # %-50s %s %.0f → %.0f", truncate(c.Title, 50), c.Field, c.From, c.To)
+		}
+	}
+	s.logger.Info("")
+	return nil
+}
+
+// DiffJSON returns the comparison of two runs or dates as indented JSON
+func (s *DiffService) DiffJSON(from, to string) ([]byte, error) {
+	diff, err := s.Compare(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode diff: %w", err)
+	}
+	return data, nil
+}
+
+// PrintRuns lists the most recent scrape runs so they can be passed to --diff
+func (s *DiffService) PrintRuns(limit int) error {
+	runs, err := s.db.GetRecentScrapeRuns(limit)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("\n RECENT SCRAPE RUNS:")
+	if len(runs) == 0 {
+		s.logger.Info("   No scrape runs recorded yet")
+	} else {
+		s.logger.Info("   %5s  %-6s %-10s %-16s %8s %8s  %s", "ID", "Kind", "Status", "Started", "Found", "Saved", "Health")
+	}
+	for _, run := range runs {
+		health := "ok"
+		if run.HealthIssues != "" {
+			health = strings.ReplaceAll(run.HealthIssues, "\n", "; ")
+		}
+		s.logger.Info("   %5d  %-6s %-10s %-16s %8d %8d  %s",
+			run.ID, run.Kind, run.Status, run.StartedAt.Format("2006-01-02 15:04"), run.ListingsFound, run.ListingsSaved, health)
+	}
+	s.logger.Info("")
+	return nil
+}
+
+// truncate shortens text to at most n runes
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// DiscoveryService records the searches found on the homepage and selects the ones to crawl
+type DiscoveryService struct {
+	db     *storage.DB
+	logger *utils.Logger
+	cfg    *config.DiscoveryConfig
+}
+
+// NewDiscoveryService creates a new discovery service
+func NewDiscoveryService(db *storage.DB, logger *utils.Logger, cfg *config.DiscoveryConfig) *DiscoveryService {
+	return &DiscoveryService{
+		db:     db,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// Record stores the discovered searches and returns the ones selected for crawling
+func (s *DiscoveryService) Record(found []models.DiscoveredLocation) ([]models.DiscoveredLocation, error) {
+	added, err := s.db.UpsertDiscoveredLocations(found)
+	if err != nil {
+		return nil, err
+	}
+	if added > 0 {
+		s.logger.Success("Discovered %d new searches on the homepage", added)
+	}
+
+	selected := s.Select(found)
+	if skipped := len(found) - len(selected); skipped > 0 {
+		s.logger.Info("Skipping %d discovered searches not selected by the discovery config", skipped)
+	}
+	return selected, nil
+}
+
+// Select keeps the searches of the configured kinds that pass the include/exclude patterns
+func (s *DiscoveryService) Select(found []models.DiscoveredLocation) []models.DiscoveredLocation {
+	include, exclude, _ := s.cfg.Patterns() // validated when the config is loaded
+
+	kinds := make(map[string]bool, len(s.cfg.Kinds))
+	for _, kind := range s.cfg.Kinds {
+		kinds[kind] = true
+	}
+
+	matches := func(d *models.DiscoveredLocation, patterns []*regexp.Regexp) bool {
+		for _, re := range patterns {
+			if re.MatchString(d.Name) || re.MatchString(d.Path()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	selected := []models.DiscoveredLocation{}
+	for i := range found {
+		d := &found[i]
+		if !kinds[d.Kind] {
+			continue
+		}
+		if len(include) > 0 && !matches(d, include) {
+			continue
+		}
+		if matches(d, exclude) {
+			continue
+		}
+		selected = append(selected, *d)
+	}
+	return selected
+}
+
+// PrintDiscovered lists every search discovered on the homepage with its first-seen date
+// and whether the discovery config selects it for crawling
+func (s *DiscoveryService) PrintDiscovered() error {
+	discovered, err := s.db.GetDiscoveredLocations()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("\n🧭 DISCOVERED SEARCHES (%d):", len(discovered))
+	if len(discovered) == 0 {
+		s.logger.Info("   Nothing discovered yet; run a scrape first\n")
+		return nil
+	}
+
+	crawled := make(map[int]bool)
+	for _, d := range s.Select(discovered) {
+		crawled[d.ID] = true
+	}
+
+	s.logger.Info("\n   %-12s %-50s %-10s %-10s %s", "Kind", "Search", "First seen", "Last seen", "Crawled")
+	s.logger.Info("   %s", strings.Repeat("-", 96))
+	for _, d := range discovered {
+		mark := "no"
+		if crawled[d.ID] {
+			mark = "yes"
+		}
+		s.logger.Info("   %-12s %-50s %-10s %-10s %s", d.Kind, truncate(d.Path(), 50),
+			d.FirstSeenAt.Format("2006-01-02"), d.LastSeenAt.Format("2006-01-02"), mark)
+	}
+	s.logger.Info("")
+	return nil
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// DuplicateService finds probable duplicate listings and stores them as clusters
+type DuplicateService struct {
+	db     *storage.DB
+	logger *utils.Logger
+	cfg    *config.DuplicatesConfig
+}
+
+// DuplicateCluster is a group of listings that probably describe the same property
+type DuplicateCluster struct {
+	ID       int // id of the lowest listing in the cluster
+	Location string
+	Listings []models.Listing
+	Score    float64 // best pair score within the cluster, 0-1
+}
+
+// duplicateMatch is a pair of listings judged to be duplicates
+type duplicateMatch struct {
+	a, b  int // indexes into the listings slice
+	score float64
+}
+
+// NewDuplicateService creates a new duplicate service
+func NewDuplicateService(db *storage.DB, logger *utils.Logger, cfg *config.DuplicatesConfig) *DuplicateService {
+	return &DuplicateService{
+		db:     db,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// Detect groups probable duplicates among listings
+// Listings are only compared within their location, and a pair never matches when known capacities
+// differ or coordinates are farther apart than MaxDistanceMeters. A pair matches when:
+//   - it is anchored: both have coordinates within MaxDistanceMeters, or both have the same known
+//     bedrooms and guests, and
+//   - the titles reach TitleSimilarity and the prices are within PriceTolerance or the coordinates are near,
+//     or, when a title is a bare "<Type> in <City>" headline and says nothing about the property,
+//     the coordinates are near and the prices are within PriceTolerance
+//
+// Clusters use complete linkage: a listing only joins a cluster when it matches every member.
+func (s *DuplicateService) Detect(listings []models.Listing) []DuplicateCluster {
+	groups := make(map[string][]int)
+	for i := range listings {
+		location := cityOf(&listings[i])
+		groups[location] = append(groups[location], i)
+	}
+
+	// Card headlines ("Apartment in Shibuya") are shared by unrelated listings and give no title evidence
+	titles := make([]string, len(listings))
+	for i := range listings {
+		if propertyType, _ := utils.ParsePropertyHeadline(listings[i].Title); propertyType != "" {
+			continue
+		}
+		titles[i] = normalizeTitle(listings[i].Title)
+	}
+
+	matches := []duplicateMatch{}
+	for _, indexes := range groups {
+		for x := 0; x < len(indexes); x++ {
+			for y := x + 1; y < len(indexes); y++ {
+				i, j := indexes[x], indexes[y]
+				if score, ok := s.match(&listings[i], &listings[j], titles[i], titles[j]); ok {
+					matches = append(matches, duplicateMatch{a: i, b: j, score: score})
+				}
+			}
+		}
+	}
+
+	// Complete linkage: merge clusters from the strongest pair down, and only when every
+	// member of one matches every member of the other, so A~B and B~C never chain A to C alone
+	sort.Slice(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	matched := make(map[[2]int]bool, len(matches))
+	for _, m := range matches {
+		matched[[2]int{m.a, m.b}] = true
+		matched[[2]int{m.b, m.a}] = true
+	}
+
+	clusterOf := make(map[int]int)
+	members := make(map[int][]int)
+	best := make(map[int]float64)
+	for _, m := range matches {
+		for _, i := range []int{m.a, m.b} {
+			if _, ok := clusterOf[i]; !ok {
+				clusterOf[i] = i
+				members[i] = []int{i}
+			}
+		}
+
+		rootA, rootB := clusterOf[m.a], clusterOf[m.b]
+		if rootA == rootB || !allMatched(members[rootA], members[rootB], matched) {
+			continue
+		}
+		for _, i := range members[rootB] {
+			clusterOf[i] = rootA
+		}
+		members[rootA] = append(members[rootA], members[rootB]...)
+		best[rootA] = math.Max(math.Max(best[rootA], best[rootB]), m.score)
+		delete(members, rootB)
+		delete(best, rootB)
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(members))
+	for root, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+		cluster := DuplicateCluster{Location: cityOf(&listings[root]), Score: best[root]}
+		for _, i := range indexes {
+			cluster.Listings = append(cluster.Listings, listings[i])
+		}
+		sort.Slice(cluster.Listings, func(a, b int) bool { return cluster.Listings[a].ID < cluster.Listings[b].ID })
+		cluster.ID = cluster.Listings[0].ID
+		clusters = append(clusters, cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Location != clusters[j].Location {
+			return clusters[i].Location < clusters[j].Location
+		}
+		return clusters[i].ID < clusters[j].ID
+	})
+	return clusters
+}
+
+// match scores a pair of listings of the same location and reports whether they are probable duplicates
+// Empty titles are headlines without title evidence. The score averages the title similarity, when
+// there is title evidence, with the price and distance proximity when known.
+func (s *DuplicateService) match(a, b *models.Listing, titleA, titleB string) (float64, bool) {
+	bedroomsKnown := (a.Bedrooms > 0 || a.Studio) && (b.Bedrooms > 0 || b.Studio)
+	if bedroomsKnown && (a.Bedrooms != b.Bedrooms || a.Studio != b.Studio) {
+		return 0, false
+	}
+	guestsKnown := a.Guests > 0 && b.Guests > 0
+	if guestsKnown && a.Guests != b.Guests {
+		return 0, false
+	}
+	sameCapacity := bedroomsKnown && guestsKnown
+
+	signals := []float64{}
+	titled := titleA != "" && titleB != ""
+	similarity := 0.0
+	if titled {
+		similarity = titleSimilarity(titleA, titleB)
+		signals = append(signals, similarity)
+	}
+
+	priceClose := false
+	if a.Price > 0 && b.Price > 0 {
+		diff := math.Abs(a.Price-b.Price) / math.Max(a.Price, b.Price)
+		priceClose = diff <= s.cfg.PriceTolerance
+		signals = append(signals, math.Max(0, 1-diff/s.cfg.PriceTolerance))
+	}
+
+	nearby := false
+	if a.Latitude != 0 && a.Longitude != 0 && b.Latitude != 0 && b.Longitude != 0 {
+		distance := haversineMeters(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		if distance > s.cfg.MaxDistanceMeters {
+			return 0, false
+		}
+		nearby = true
+		signals = append(signals, 1-distance/s.cfg.MaxDistanceMeters)
+	}
+
+	if !nearby && !sameCapacity {
+		return 0, false
+	}
+	if titled {
+		if similarity < s.cfg.TitleSimilarity || !(priceClose || nearby) {
+			return 0, false
+		}
+	} else if !(nearby && priceClose) {
+		return 0, false
+	}
+	return mean(signals), true
+}
+
+// allMatched reports whether every listing of a matches every listing of b
+func allMatched(a, b []int, matched map[[2]int]bool) bool {
+	for _, i := range a {
+		for _, j := range b {
+			if !matched[[2]int{i, j}] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// normalizeTitle lowercases a title and reduces it to letters and digits separated by single spaces
+func normalizeTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// titleSimilarity returns the Dice coefficient of the character bigrams of two normalized titles
+func titleSimilarity(a, b string) float64 {
+	if a == b {
+		if a == "" {
+			return 0
+		}
+		return 1
+	}
+
+	bigrams := func(text string) map[string]int {
+		runes := []rune(text)
+		counts := make(map[string]int)
+		for i := 0; i+1 < len(runes); i++ {
+			counts[string(runes[i:i+2])]++
+		}
+		return counts
+	}
+
+	countsA, countsB := bigrams(a), bigrams(b)
+	totalA, totalB, shared := 0, 0, 0
+	for bigram, n := range countsA {
+		totalA += n
+		if m, ok := countsB[bigram]; ok {
+			if m < n {
+				shared += m
+			} else {
+				shared += n
+			}
+		}
+	}
+	for _, n := range countsB {
+		totalB += n
+	}
+
+	if totalA+totalB == 0 {
+		return 0
+	}
+	return 2 * float64(shared) / float64(totalA+totalB)
+}
+
+// haversineMeters returns the great-circle distance between two coordinates in meters
+func haversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371000.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// FindDuplicates detects duplicate clusters among all listings and replaces the stored clusters
+func (s *DuplicateService) FindDuplicates() ([]DuplicateCluster, error) {
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+
+	clusters := s.Detect(listings)
+
+	assignments := make(map[int]int)
+	scores := make(map[int]float64)
+	duplicates := 0
+	for _, cluster := range clusters {
+		for _, listing := range cluster.Listings {
+			assignments[listing.ID] = cluster.ID
+			scores[listing.ID] = cluster.Score
+		}
+		duplicates += len(cluster.Listings) - 1
+	}
+
+	if err := s.db.ReplaceDuplicateClusters(assignments, scores); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Found %d probable duplicate clusters (%d extra listings)", len(clusters), duplicates)
+	return clusters, nil
+}
+
+// PrintDuplicates detects and stores duplicate clusters, then lists them per location
+func (s *DuplicateService) PrintDuplicates() error {
+	clusters, err := s.FindDuplicates()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("\n PROBABLE DUPLICATE LISTINGS:")
+	if len(clusters) == 0 {
+		s.logger.Info("   No duplicates found\n")
+		return nil
+	}
+
+	for i, cluster := range clusters {
+		s.logger.Info("\n   %d. %s (%d listings, score %.2f)", i+1, cluster.Location, len(cluster.Listings), cluster.Score)
+		for _, listing := range cluster.Listings {
+			s.logger.Info("      - %s | $%.2f | %d bedrooms, %d guests", listing.Title, listing.Price, listing.Bedrooms, listing.Guests)
+			s.logger.Info("        %s", listing.URL)
+		}
+	}
+	s.logger.Info("\n   Pass --count-duplicates-once (or set analytics.count_duplicates_once) to count each cluster once\n")
+	return nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// HealthService checks the metrics of a scrape run against the configured thresholds
+type HealthService struct {
+	db     *storage.DB
+	logger *utils.Logger
+	cfg    *config.HealthConfig
+}
+
+// LocationHealth compares the listings a location returned with its recent baseline
+type LocationHealth struct {
+	Location string
+	Listings int
+	Baseline float64 // median of recent healthy runs, 0 if there is no history
+}
+
+// RunHealth holds the health metrics of a run and the thresholds it breached
+type RunHealth struct {
+	CardsPerPage      float64
+	DetailSuccessRate float64
+	EmptyFieldShare   float64
+	EmptyFields       map[string]float64
+	Locations         []LocationHealth
+	Issues            []string
+}
+
+// Healthy reports whether no threshold was breached
+func (h *RunHealth) Healthy() bool {
+	return len(h.Issues) == 0
+}
+
+// NewHealthService creates a new health service
+func NewHealthService(db *storage.DB, logger *utils.Logger, cfg *config.HealthConfig) *HealthService {
+	return &HealthService{
+		db:     db,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// EmptyFieldShares returns the share of raw listings missing each card and detail field
+func EmptyFieldShares(listings []models.RawListing) map[string]float64 {
+	shares := map[string]float64{
+		"title": 0, "price": 0, "location": 0, "rating": 0,
+		"bedrooms": 0, "bathrooms": 0, "guests": 0,
+	}
+	if len(listings) == 0 {
+		return shares
+	}
+
+	for _, listing := range listings {
+		empty := map[string]bool{
+			"title":     listing.Title == "",
+			"price":     listing.Price == "",
+			"location":  listing.Location == "",
+			"rating":    listing.Rating == "",
+			"bedrooms":  listing.Bedrooms == 0 && !listing.Studio,
+			"bathrooms": listing.Bathrooms == 0 && listing.HalfBaths == 0,
+			"guests":    listing.Guests == 0,
+		}
+		for field, isEmpty := range empty {
+			if isEmpty {
+				shares[field]++
+			}
+		}
+	}
+
+	for field := range shares {
+		shares[field] /= float64(len(listings))
+	}
+	return shares
+}
+
+// Check computes the health of a run, stores its per-location counts and records the metrics
+// and breached thresholds on the run
+// locationCounts maps each searched location to the number of listings it returned, nil for area crawls
+func (s *HealthService) Check(run *models.ScrapeRun, emptyFields map[string]float64, locationCounts map[string]int) (*RunHealth, error) {
+	health := &RunHealth{EmptyFields: emptyFields}
+
+	if len(emptyFields) > 0 {
+		var total float64
+		for _, share := range emptyFields {
+			total += share
+		}
+		health.EmptyFieldShare = total / float64(len(emptyFields))
+	}
+	run.EmptyFieldShare = health.EmptyFieldShare
+
+	if run.PagesScraped > 0 {
+		health.CardsPerPage = float64(run.CardsFound) / float64(run.PagesScraped)
+	}
+	if run.DetailsAttempted > 0 {
+		health.DetailSuccessRate = float64(run.DetailsSucceeded) / float64(run.DetailsAttempted)
+	}
+
+	// Watchlist refreshes visit detail pages only, so they have no search pages
+	if run.ListingsFound == 0 || (run.PagesScraped == 0 && run.Kind != models.RunKindWatch) {
+		health.Issues = append(health.Issues, "no listings scraped")
+	}
+	// Map tiles are split until they hold few results, and saved searches and wishlists are
+	// as narrow as their owner made them, so their pages are legitimately short
+	if run.Kind != models.RunKindArea && run.Kind != models.RunKindSeed && run.PagesScraped > 0 && health.CardsPerPage < s.cfg.MinCardsPerPage {
+		health.Issues = append(health.Issues, fmt.Sprintf("%.1f cards per page (minimum %.1f)",
+			health.CardsPerPage, s.cfg.MinCardsPerPage))
+	}
+	if run.DetailsAttempted > 0 && health.DetailSuccessRate < s.cfg.MinDetailSuccessRate {
+		health.Issues = append(health.Issues, fmt.Sprintf("detail success rate %.0f%% (minimum %.0f%%)",
+			health.DetailSuccessRate*100, s.cfg.MinDetailSuccessRate*100))
+	}
+	if run.ListingsFound > 0 && health.EmptyFieldShare > s.cfg.MaxEmptyFieldShare {
+		health.Issues = append(health.Issues, fmt.Sprintf("%.0f%% of fields empty (maximum %.0f%%)",
+			health.EmptyFieldShare*100, s.cfg.MaxEmptyFieldShare*100))
+	}
+
+	if len(locationCounts) > 0 {
+		if err := s.db.SaveRunLocationCounts(run.ID, locationCounts); err != nil {
+			return nil, err
+		}
+
+		baselines, err := s.db.GetLocationBaselines(run.ID, s.cfg.BaselineRuns)
+		if err != nil {
+			return nil, err
+		}
+
+		for location, count := range locationCounts {
+			lh := LocationHealth{Location: location, Listings: count, Baseline: baselines[location]}
+			health.Locations = append(health.Locations, lh)
+
+			if lh.Baseline > 0 && float64(count) < lh.Baseline*s.cfg.MinBaselineRatio {
+				health.Issues = append(health.Issues, fmt.Sprintf("%s returned %d listings (baseline %.0f)",
+					location, count, lh.Baseline))
+			}
+		}
+		sort.Slice(health.Locations, func(i, j int) bool { return health.Locations[i].Location < health.Locations[j].Location })
+	}
+
+	sort.Strings(health.Issues)
+	run.HealthIssues = strings.Join(health.Issues, "\n")
+	return health, nil
+}
+
+// PrintHealth prints the health metrics of a run and the thresholds it breached
+func (s *HealthService) PrintHealth(health *RunHealth) {
+	s.logger.Info("Cards per page:       %.1f (minimum %.1f)", health.CardsPerPage, s.cfg.MinCardsPerPage)
+	s.logger.Info("Detail success rate:  %.0f%% (minimum %.0f%%)", health.DetailSuccessRate*100, s.cfg.MinDetailSuccessRate*100)
+	s.logger.Info("Empty fields:         %.0f%% (maximum %.0f%%)", health.EmptyFieldShare*100, s.cfg.MaxEmptyFieldShare*100)
+
+	fields := make([]string, 0, len(health.EmptyFields))
+	for field := range health.EmptyFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if share := health.EmptyFields[field]; share > 0 {
+			s.logger.Info("   %-10s %3.0f%% empty", field, share*100)
+		}
+	}
+
+	for _, lh := range health.Locations {
+		if lh.Baseline > 0 {
+			s.logger.Info("%-35s %4d listings (baseline %.0f)", lh.Location, lh.Listings, lh.Baseline)
+		} else {
+			s.logger.Info("%-35s %4d listings (no baseline yet)", lh.Location, lh.Listings)
+		}
+	}
+
+	if health.Healthy() {
+		s.logger.Success("Scrape health OK")
+		return
+	}
+	for _, issue := range health.Issues {
+		s.logger.Error("Health check failed: %s", issue)
+	}
+}
@@ -23,7 +23,8 @@ func NewListingService(db *storage.DB, logger *utils.Logger) *ListingService {
 }
 
 // NormalizeAndSave converts raw listings to normalized listings and saves to database
-func (scrape *ListingService) NormalizeAndSave(rawListings []models.RawListing) (int, error) {
+// Each saved listing is also recorded as a snapshot of the given scrape run (0 = no run)
+func (scrape *ListingService) NormalizeAndSave(runID int, rawListings []models.RawListing) (int, error) {
 	if len(rawListings) == 0 {
 		return 0, fmt.Errorf("no listings to save")
 	}
@@ -41,6 +42,11 @@ func (scrape *ListingService) NormalizeAndSave(rawListings []models.RawListing)
 			continue
 		}
 
+		if listing.ListingID == "" {
+			scrape.logger.Warning("Skipping listing without a room ID: %s", listing.URL)
+			continue
+		}
+
 		// Save to database (ON CONFLICT handles duplicates)
 		err := scrape.db.InsertListing(&listing)
 		if err != nil {
@@ -49,6 +55,11 @@ func (scrape *ListingService) NormalizeAndSave(rawListings []models.RawListing)
 			continue
 		}
 
+		// Record price history
+		if err := scrape.db.InsertSnapshot(runID, &listing); err != nil {
+			scrape.logger.Error("Failed to record snapshot for '%s': %v", listing.Title, err)
+		}
+
 		successCount++
 		scrape.logger.Info("✓ Saved: %s ($%.2f)", listing.Title, listing.Price)
 	}
@@ -64,15 +75,44 @@ func (scrape *ListingService) NormalizeAndSave(rawListings []models.RawListing)
 
 // normalize converts RawListing to normalized Listing
 func (s *ListingService) normalize(raw models.RawListing) models.Listing {
+	listingID := utils.ExtractListingID(raw.URL)
+
+	url := utils.NormalizeURL(raw.URL) //removing query params as it keeps changing and duplicate data gets added.
+	if listingID != "" {
+		url = utils.CanonicalListingURL(listingID) // airbnb.co.uk/rooms/plus/123 -> airbnb.com/rooms/123
+	}
+
+	// Card headlines read "Loft in Richmond"; the location field is the most reliable source
+	// The title is only used when it reads "<type> in <city>" too, so a plain title never becomes the city
+	propertyType, city := utils.ParsePropertyHeadline(raw.Location)
+	if propertyType == "" {
+		if titleType, titleCity := utils.ParsePropertyHeadline(raw.Title); titleType != "" {
+			propertyType, city = titleType, titleCity
+		}
+	}
+
 	return models.Listing{
-		Title:     raw.Title,
-		Price:     utils.NormalizePrice(raw.Price), // "$120" -> 120.0
-		Location:  raw.Location,
-		Rating:    utils.NormalizeRating(raw.Rating), // "4.95 (123)" -> 4.95
-		URL:       utils.NormalizeURL(raw.URL),       //removing query params as it keeps changing and duplicate data gets added.
-		Bedrooms:  raw.Bedrooms,
-		Bathrooms: raw.Bathrooms,
-		Guests:    raw.Guests,
+		ListingID:      listingID,
+		Title:          raw.Title,
+		Price:          utils.NormalizePrice(raw.Price), // "$120" -> 120.0
+		Location:       raw.Location,
+		SearchLocation: raw.SearchLocation,
+		PropertyType:   propertyType,
+		City:           city,
+		Rating:         utils.NormalizeRating(raw.Rating),      // "4.95 (123)" -> 4.95
+		ReviewCount:    utils.NormalizeReviewCount(raw.Rating), // "4.95 (123)" -> 123
+		URL:            url,
+		Bedrooms:       raw.Bedrooms,
+		Beds:           raw.Beds,
+		Bathrooms:      raw.Bathrooms,
+		HalfBaths:      raw.HalfBaths,
+		BathroomType:   raw.BathroomType,
+		Studio:         raw.Studio,
+		Guests:         raw.Guests,
+		Confidence:     raw.Confidence,
+		Latitude:       raw.Latitude,
+		Longitude:      raw.Longitude,
+		Amenities:      raw.Amenities,
 	}
 }
 
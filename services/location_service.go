@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// LocationService resolves free-text locations to canonical cities
+type LocationService struct {
+	db        *storage.DB
+	logger    *utils.Logger
+	gazetteer *utils.Gazetteer
+	saved     map[int64]*models.Location // GeoNames ID -> stored location
+}
+
+// NewLocationService creates a new location service
+// A nil gazetteer disables resolution
+func NewLocationService(db *storage.DB, logger *utils.Logger, gazetteer *utils.Gazetteer) *LocationService {
+	return &LocationService{
+		db:        db,
+		logger:    logger,
+		gazetteer: gazetteer,
+		saved:     make(map[int64]*models.Location),
+	}
+}
+
+// Resolve maps free text to a stored canonical location
+// hint is an optional broader place (e.g. the search location) used to break ties
+// Returns nil if the text cannot be resolved
+func (s *LocationService) Resolve(text, hint string) (*models.Location, error) {
+	if s.gazetteer == nil {
+		return nil, nil
+	}
+
+	place := s.gazetteer.Resolve(text, hint)
+	if place == nil {
+		return nil, nil
+	}
+
+	if location, ok := s.saved[place.GeonameID]; ok {
+		return location, nil
+	}
+
+	location := &models.Location{
+		GeonameID:   place.GeonameID,
+		City:        place.City,
+		Region:      place.Region,
+		Country:     place.Country,
+		CountryCode: place.CountryCode,
+		Latitude:    place.Latitude,
+		Longitude:   place.Longitude,
+	}
+
+	if err := s.db.UpsertLocation(location); err != nil {
+		return nil, err
+	}
+
+	s.saved[place.GeonameID] = location
+	return location, nil
+}
+
+// ResolveListings links every unresolved listing to a canonical location
+// The parsed city is tried first, then the raw location, then the search location
+// Returns the number of listings resolved
+func (s *LocationService) ResolveListings() (int, error) {
+	if s.gazetteer == nil {
+		s.logger.Warning("No gazetteer loaded, skipping location resolution")
+		return 0, nil
+	}
+
+	listings, err := s.db.GetUnresolvedListings()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unresolved listings: %w", err)
+	}
+
+	resolved := 0
+	unresolved := make(map[string]int)
+
+	for _, listing := range listings {
+		var location *models.Location
+		for _, text := range []string{listing.City, listing.Location, listing.SearchLocation} {
+			if text == "" {
+				continue
+			}
+
+			location, err = s.Resolve(text, listing.SearchLocation)
+			if err != nil {
+				return resolved, err
+			}
+			if location != nil {
+				break
+			}
+		}
+
+		if location == nil {
+			unresolved[cityOf(&listing)]++
+			continue
+		}
+
+		if err := s.db.SetListingLocation(listing.ID, location.ID); err != nil {
+			return resolved, err
+		}
+		resolved++
+	}
+
+	s.logger.Success("Resolved %d of %d listings to canonical locations", resolved, len(listings))
+	for text, count := range unresolved {
+		s.logger.Warning("Unresolved location %q (%d listings)", text, count)
+	}
+
+	return resolved, nil
+}
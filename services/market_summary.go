@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// LocationSummary is one row of the market summary
+type LocationSummary struct {
+	Location    string // location, or segment label when grouped by segment
+	Count       int
+	MedianPrice float64
+	MeanPrice   float64
+	MeanRating  float64 // over rated listings only
+	AvgBedrooms float64
+	AvgGuests   float64
+	TopShare    float64 // share of listings rated above 4.8 (0-1)
+}
+
+// topRatingThreshold is the rating a listing must exceed to count toward TopShare
+const topRatingThreshold = 4.8
+
+// summaryColumns maps --sort values to the column they sort by
+var summaryColumns = map[string]func(a, b *LocationSummary) bool{
+	"location": func(a, b *LocationSummary) bool { return a.Location < b.Location },
+	"count":    func(a, b *LocationSummary) bool { return a.Count < b.Count },
+	"median":   func(a, b *LocationSummary) bool { return a.MedianPrice < b.MedianPrice },
+	"mean":     func(a, b *LocationSummary) bool { return a.MeanPrice < b.MeanPrice },
+	"rating":   func(a, b *LocationSummary) bool { return a.MeanRating < b.MeanRating },
+	"bedrooms": func(a, b *LocationSummary) bool { return a.AvgBedrooms < b.AvgBedrooms },
+	"guests":   func(a, b *LocationSummary) bool { return a.AvgGuests < b.AvgGuests },
+	"top":      func(a, b *LocationSummary) bool { return a.TopShare < b.TopShare },
+}
+
+// SummaryColumns lists the columns the market summary can be sorted by
+func SummaryColumns() []string {
+	columns := make([]string, 0, len(summaryColumns))
+	for column := range summaryColumns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// summaryGroups maps --summary-by values to the key listings are grouped by
+var summaryGroups = map[string]func(l *models.Listing) string{
+	"location": cityOf,
+	"segment":  segmentOf,
+}
+
+// segmentOf returns the market segment a listing is grouped under
+func segmentOf(listing *models.Listing) string {
+	if listing.Segment != "" {
+		return listing.Segment
+	}
+	return "Unsegmented"
+}
+
+// GetMarketSummary builds the breakdown per location or per segment sorted by the given column
+func (s *AnalyticsService) GetMarketSummary(groupBy, sortBy string, descending bool) ([]LocationSummary, error) {
+	less, ok := summaryColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort column %q (use one of: %s)", sortBy, strings.Join(SummaryColumns(), ", "))
+	}
+	keyOf, ok := summaryGroups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown summary grouping %q (use location or segment)", groupBy)
+	}
+
+	listings, _, err := s.loadListings()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := summarizeBy(listings, keyOf)
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if descending {
+			return less(&summaries[j], &summaries[i])
+		}
+		return less(&summaries[i], &summaries[j])
+	})
+
+	return summaries, nil
+}
+
+// summarizeBy aggregates listings into one summary per group key
+func summarizeBy(listings []models.Listing, keyOf func(l *models.Listing) string) []LocationSummary {
+	groups := make(map[string][]*models.Listing)
+	order := []string{}
+
+	for i := range listings {
+		location := keyOf(&listings[i])
+		if _, ok := groups[location]; !ok {
+			order = append(order, location)
+		}
+		groups[location] = append(groups[location], &listings[i])
+	}
+
+	summaries := make([]LocationSummary, 0, len(groups))
+	for _, location := range order {
+		group := groups[location]
+		summary := LocationSummary{Location: location, Count: len(group)}
+
+		prices := []float64{}
+		var ratingTotal float64
+		var rated, topRated, bedrooms, guests int
+
+		for _, listing := range group {
+			if listing.Price > 0 {
+				prices = append(prices, listing.Price)
+			}
+			if listing.Rating > 0 {
+				ratingTotal += listing.Rating
+				rated++
+			}
+			if listing.Rating > topRatingThreshold {
+				topRated++
+			}
+			bedrooms += listing.Bedrooms
+			guests += listing.Guests
+		}
+
+		sort.Float64s(prices)
+		summary.MedianPrice = percentile(prices, 50)
+		summary.MeanPrice = mean(prices)
+		if rated > 0 {
+			summary.MeanRating = ratingTotal / float64(rated)
+		}
+		summary.AvgBedrooms = float64(bedrooms) / float64(len(group))
+		summary.AvgGuests = float64(guests) / float64(len(group))
+		summary.TopShare = float64(topRated) / float64(len(group))
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+// PrintMarketSummary prints the breakdown per location or per segment as an aligned table
+func (s *AnalyticsService) PrintMarketSummary(groupBy, sortBy string, descending bool) error {
+	summaries, err := s.GetMarketSummary(groupBy, sortBy, descending)
+	if err != nil {
+		return err
+	}
+
+	if len(summaries) == 0 {
+		s.logger.Info("\nNo listings yet\n")
+		return nil
+	}
+
+	heading := "Location"
+	if groupBy == "segment" {
+		heading = "Segment"
+	}
+
+	// Widths count runes, as fmt pads by runes: "Zürich" is 6 wide, not 7
+	width := utf8.RuneCountInString(heading)
+	for _, summary := range summaries {
+		if n := utf8.RuneCountInString(summary.Location); n > width {
+			width = n
+		}
+	}
+
+	s.logger.Info("\n MARKET SUMMARY BY %s (sorted by %s):", strings.ToUpper(heading), sortBy)
+	s.logger.Info("   %-*s %6s %10s %10s %7s %9s %7s %8s",
+		width, heading, "Count", "Median", "Mean", "Rating", "Bedrooms", "Guests", ">4.8")
+	s.logger.Info("   %s", strings.Repeat("-", width+66))
+
+	for _, summary := range summaries {
+		s.logger.Info("   %-*s %6d %10.2f %10.2f %7.2f %9.1f %7.1f %7.0f%%",
+			width, summary.Location, summary.Count, summary.MedianPrice, summary.MeanPrice,
+			summary.MeanRating, summary.AvgBedrooms, summary.AvgGuests, summary.TopShare*100)
+	}
+	s.logger.Info("")
+	return nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// Notifier delivers alerts to one channel
+type Notifier interface {
+	Name() string
+	Send(alert *models.Alert) error
+}
+
+// WebhookNotifier posts each alert as JSON to a URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the channel name used in logs
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Send posts the alert and fails on any non-2xx response
+func (n *WebhookNotifier) Send(alert *models.Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// SMTPNotifier mails each alert to the configured recipients
+type SMTPNotifier struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTPNotifier creates a notifier sending through the configured mail server
+func NewSMTPNotifier(cfg config.SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+// Name returns the channel name used in logs
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+// Send mails the alert as plain text
+// Authentication is only used when a username is configured
+func (n *SMTPNotifier) Send(alert *models.Alert) error {
+	addr := fmt.Sprintf("%s:%d", n.cfg.Host, n.cfg.Port)
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + n.cfg.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(n.cfg.To, ", ") + "\r\n")
+	msg.WriteString("Subject: " + alert.Subject + "\r\n")
+	msg.WriteString("Date: " + alert.TriggeredAt.Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(alert.Message, "\n", "\r\n") + "\r\n")
+
+	if err := smtp.SendMail(addr, auth, n.cfg.From, n.cfg.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	return nil
+}
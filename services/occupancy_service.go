@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// OccupancyService stores availability calendars and estimates occupancy and revenue from them
+type OccupancyService struct {
+	db     *storage.DB
+	logger *utils.Logger
+}
+
+// ListingOccupancy is the estimated occupancy and revenue of one listing over a window of nights
+// Unavailable nights are assumed booked, so occupancy is an upper bound (owner blocks count as booked)
+type ListingOccupancy struct {
+	Listing      models.Listing
+	Location     string
+	Nights       int
+	BookedNights int
+	Occupancy    float64 // booked / nights, 0-1
+	ADR          float64 // average daily rate of booked nights
+	RevPAR       float64 // revenue per available night = revenue / nights
+	Revenue      float64 // estimated revenue over the window
+}
+
+// LocationOccupancy aggregates the listings of one location
+type LocationOccupancy struct {
+	Location     string
+	Listings     int
+	Nights       int
+	BookedNights int
+	Occupancy    float64
+	ADR          float64
+	RevPAR       float64
+	Revenue      float64
+}
+
+// NewOccupancyService creates a new occupancy service
+func NewOccupancyService(db *storage.DB, logger *utils.Logger) *OccupancyService {
+	return &OccupancyService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveCalendars stores scraped calendars keyed by listing URL for the matching listings
+// Returns the number of listings whose calendar was saved
+func (s *OccupancyService) SaveCalendars(listings []models.Listing, calendars map[string][]models.CalendarDay) int {
+	saved := 0
+	for _, listing := range listings {
+		days, ok := calendars[listing.URL]
+		if !ok || len(days) == 0 {
+			continue
+		}
+
+		if err := s.db.UpsertCalendar(listing.ID, days); err != nil {
+			s.logger.Error("Failed to save calendar for '%s': %v", listing.Title, err)
+			continue
+		}
+		saved++
+	}
+
+	s.logger.Success("Saved calendars for %d listings", saved)
+	return saved
+}
+
+// GetOccupancy estimates occupancy, ADR and RevPAR per listing and per location over the next days nights
+func (s *OccupancyService) GetOccupancy(days int) ([]ListingOccupancy, []LocationOccupancy, error) {
+	summaries, err := s.db.GetCalendarSummaries(days)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+	byID := make(map[int]*models.Listing, len(listings))
+	for i := range listings {
+		byID[listings[i].ID] = &listings[i]
+	}
+
+	perListing := []ListingOccupancy{}
+	groups := make(map[string]*LocationOccupancy)
+
+	for _, summary := range summaries {
+		listing, ok := byID[summary.ListingID]
+		if !ok || summary.Nights == 0 {
+			continue
+		}
+
+		adr := summary.BookedPriceAvg
+		if adr == 0 {
+			adr = summary.PriceAvg
+		}
+		if adr == 0 {
+			adr = listing.Price
+		}
+
+		o := ListingOccupancy{
+			Listing:      *listing,
+			Location:     cityOf(listing),
+			Nights:       summary.Nights,
+			BookedNights: summary.BookedNights,
+			Occupancy:    float64(summary.BookedNights) / float64(summary.Nights),
+			ADR:          adr,
+			Revenue:      adr * float64(summary.BookedNights),
+		}
+		o.RevPAR = o.Revenue / float64(o.Nights)
+		perListing = append(perListing, o)
+
+		g := groups[o.Location]
+		if g == nil {
+			g = &LocationOccupancy{Location: o.Location}
+			groups[o.Location] = g
+		}
+		g.Listings++
+		g.Nights += o.Nights
+		g.BookedNights += o.BookedNights
+		g.Revenue += o.Revenue
+	}
+
+	perLocation := make([]LocationOccupancy, 0, len(groups))
+	for _, g := range groups {
+		g.Occupancy = float64(g.BookedNights) / float64(g.Nights)
+		if g.BookedNights > 0 {
+			g.ADR = g.Revenue / float64(g.BookedNights)
+		}
+		g.RevPAR = g.Revenue / float64(g.Nights)
+		perLocation = append(perLocation, *g)
+	}
+
+	sort.Slice(perListing, func(i, j int) bool { return perListing[i].Revenue > perListing[j].Revenue })
+	sort.Slice(perLocation, func(i, j int) bool { return perLocation[i].RevPAR > perLocation[j].RevPAR })
+	return perListing, perLocation, nil
+}
+
+// PrintOccupancy prints occupancy, ADR and RevPAR per location and the n highest-revenue listings
+func (s *OccupancyService) PrintOccupancy(days, n int) error {
+	perListing, perLocation, err := s.GetOccupancy(days)
+	if err != nil {
+		return err
+	}
+
+	if len(perLocation) == 0 {
+		s.logger.Info("\nNo calendar data for the next %d nights; run with --scrape-calendars first\n", days)
+		return nil
+	}
+
+	width := utf8.RuneCountInString("Location")
+	for _, g := range perLocation {
+		if n := utf8.RuneCountInString(g.Location); n > width {
+			width = n
+		}
+	}
+
+	s.logger.Info("\n🏨 OCCUPANCY AND REVENUE (next %d nights, unavailable nights counted as booked):", days)
+	s.logger.Info("   %-*s %8s %10s %10s %10s %12s", width, "Location", "Listings", "Occupancy", "ADR", "RevPAR", "Revenue")
+	s.logger.Info("   %s", strings.Repeat("-", width+56))
+	for _, g := range perLocation {
+		s.logger.Info("   %-*s %8d %9.0f%% %10.2f %10.2f %12.2f",
+			width, g.Location, g.Listings, g.Occupancy*100, g.ADR, g.RevPAR, g.Revenue)
+	}
+
+	if len(perListing) > n {
+		perListing = perListing[:n]
+	}
+
+	s.logger.Info("\n   Highest estimated revenue:")
+	for i, o := range perListing {
+		s.logger.Info("\n   %d. %s", i+1, o.Listing.Title)
+		s.logger.Info("      Revenue: $%.2f | Occupancy: %.0f%% (%d/%d nights) | ADR: $%.2f | RevPAR: $%.2f",
+			o.Revenue, o.Occupancy*100, o.BookedNights, o.Nights, o.ADR, o.RevPAR)
+		s.logger.Info("      Location: %s | URL: %s", o.Location, o.Listing.URL)
+	}
+	s.logger.Info("")
+	return nil
+}
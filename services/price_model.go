@@ -0,0 +1,386 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// minTrainingListings is the smallest number of priced listings a model is trained on
+const minTrainingListings = 10
+
+// FairPriceService trains the fair-price model and compares listing prices with its predictions
+type FairPriceService struct {
+	db       *storage.DB
+	logger   *utils.Logger
+	cfg      *config.PriceModelConfig
+	detector *QualityDetector
+}
+
+// PriceModel is a ridge regression of log price on listing features
+type PriceModel struct {
+	locations     map[string]int // category -> column
+	propertyTypes map[string]int
+	means         []float64 // numeric feature means for standardization
+	stds          []float64
+	weights       []float64 // intercept first
+}
+
+// PricePrediction compares a listing's price with the model's expected price
+type PricePrediction struct {
+	Listing   models.Listing
+	Location  string
+	Predicted float64
+	Residual  float64 // (price - predicted) / predicted, negative = under-priced
+}
+
+// numericFeatureCount is the number of numeric features before the one-hot categories
+const numericFeatureCount = 6
+
+// NewFairPriceService creates a new fair-price service
+// Training leaves out listings flagged by the data quality checks
+func NewFairPriceService(db *storage.DB, logger *utils.Logger, cfg *config.PriceModelConfig, analyticsCfg *config.AnalyticsConfig) *FairPriceService {
+	return &FairPriceService{
+		db:       db,
+		logger:   logger,
+		cfg:      cfg,
+		detector: NewQualityDetector(analyticsCfg),
+	}
+}
+
+// numericFeatures returns the raw numeric features of a listing:
+// bedrooms, bathrooms, guests, rating (0 when unrated), rated indicator, log(1 + review count)
+func numericFeatures(l *models.Listing) []float64 {
+	rated := 0.0
+	if l.Rating > 0 {
+		rated = 1
+	}
+	return []float64{
+		float64(l.Bedrooms),
+		float64(l.Bathrooms),
+		float64(l.Guests),
+		l.Rating,
+		rated,
+		math.Log1p(float64(l.ReviewCount)),
+	}
+}
+
+// categoryColumns assigns a column to every category seen at least minCount times, starting at offset
+func categoryColumns(values []string, minCount, offset int) map[string]int {
+	counts := make(map[string]int)
+	for _, value := range values {
+		counts[value]++
+	}
+
+	kept := []string{}
+	for value, count := range counts {
+		if count >= minCount {
+			kept = append(kept, value)
+		}
+	}
+	sort.Strings(kept)
+
+	columns := make(map[string]int, len(kept))
+	for i, value := range kept {
+		columns[value] = offset + i
+	}
+	return columns
+}
+
+// TrainPriceModel fits a ridge regression of log price on the listings
+// Numeric features are standardized, locations and property types one-hot encoded;
+// the intercept is not penalized
+func TrainPriceModel(listings []models.Listing, lambda float64, minCategoryCount int) *PriceModel {
+	model := &PriceModel{}
+
+	locations := make([]string, len(listings))
+	propertyTypes := make([]string, len(listings))
+	for i := range listings {
+		locations[i] = cityOf(&listings[i])
+		propertyTypes[i] = propertyTypeOf(&listings[i])
+	}
+	model.locations = categoryColumns(locations, minCategoryCount, 1+numericFeatureCount)
+	model.propertyTypes = categoryColumns(propertyTypes, minCategoryCount, 1+numericFeatureCount+len(model.locations))
+
+	model.means = make([]float64, numericFeatureCount)
+	model.stds = make([]float64, numericFeatureCount)
+	raw := make([][]float64, len(listings))
+	for i := range listings {
+		raw[i] = numericFeatures(&listings[i])
+		for j, v := range raw[i] {
+			model.means[j] += v
+		}
+	}
+	for j := range model.means {
+		model.means[j] /= float64(len(listings))
+	}
+	for i := range raw {
+		for j, v := range raw[i] {
+			model.stds[j] += (v - model.means[j]) * (v - model.means[j])
+		}
+	}
+	for j := range model.stds {
+		model.stds[j] = math.Sqrt(model.stds[j] / float64(len(listings)))
+	}
+
+	// Normal equations: (XᵀX + λI') w = Xᵀy, with I' skipping the intercept
+	p := model.featureCount()
+	xtx := make([][]float64, p)
+	for j := range xtx {
+		xtx[j] = make([]float64, p)
+	}
+	xty := make([]float64, p)
+
+	for i := range listings {
+		x := model.features(&listings[i])
+		y := math.Log(listings[i].Price)
+		for a := 0; a < p; a++ {
+			if x[a] == 0 {
+				continue
+			}
+			xty[a] += x[a] * y
+			for b := 0; b < p; b++ {
+				xtx[a][b] += x[a] * x[b]
+			}
+		}
+	}
+	for j := 1; j < p; j++ {
+		xtx[j][j] += lambda
+	}
+
+	model.weights = solveLinear(xtx, xty)
+	return model
+}
+
+// featureCount returns the number of columns including the intercept
+func (m *PriceModel) featureCount() int {
+	return 1 + numericFeatureCount + len(m.locations) + len(m.propertyTypes)
+}
+
+// features builds the design row of a listing
+func (m *PriceModel) features(l *models.Listing) []float64 {
+	x := make([]float64, m.featureCount())
+	x[0] = 1
+
+	for j, v := range numericFeatures(l) {
+		if m.stds[j] > 0 {
+			x[1+j] = (v - m.means[j]) / m.stds[j]
+		}
+	}
+	if column, ok := m.locations[cityOf(l)]; ok {
+		x[column] = 1
+	}
+	if column, ok := m.propertyTypes[propertyTypeOf(l)]; ok {
+		x[column] = 1
+	}
+	return x
+}
+
+// Predict returns the expected nightly price of a listing
+func (m *PriceModel) Predict(l *models.Listing) float64 {
+	var logPrice float64
+	for j, v := range m.features(l) {
+		logPrice += m.weights[j] * v
+	}
+	return math.Exp(logPrice)
+}
+
+// solveLinear solves a·x = b by Gaussian elimination with partial pivoting
+// Columns that are numerically singular get a zero coefficient
+func solveLinear(a [][]float64, b []float64) []float64 {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		if math.Abs(a[col][col]) < 1e-12 {
+			continue
+		}
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		if math.Abs(a[row][row]) < 1e-12 {
+			continue
+		}
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x
+}
+
+// evaluate returns the mean absolute error and R² of the model's price predictions
+func (m *PriceModel) evaluate(listings []models.Listing) (float64, float64) {
+	prices := make([]float64, len(listings))
+	for i := range listings {
+		prices[i] = listings[i].Price
+	}
+	avg := mean(prices)
+
+	var absErr, ssRes, ssTot float64
+	for i := range listings {
+		predicted := m.Predict(&listings[i])
+		diff := listings[i].Price - predicted
+		absErr += math.Abs(diff)
+		ssRes += diff * diff
+		ssTot += (listings[i].Price - avg) * (listings[i].Price - avg)
+	}
+
+	r2 := 0.0
+	if ssTot > 0 {
+		r2 = 1 - ssRes/ssTot
+	}
+	return absErr / float64(len(listings)), r2
+}
+
+// Train evaluates the model on a held-out split, stores the metrics, and returns a model
+// refitted on all clean priced listings together with every priced listing
+func (s *FairPriceService) Train() (*PriceModel, []models.Listing, *models.ModelRun, error) {
+	all, err := s.db.GetAllListings()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+
+	priced := make([]models.Listing, 0, len(all))
+	for _, listing := range all {
+		if listing.Price > 0 {
+			priced = append(priced, listing)
+		}
+	}
+
+	clean, _ := s.detector.Filter(priced)
+	if len(clean) < minTrainingListings {
+		return nil, nil, nil, fmt.Errorf("need at least %d priced listings to train, have %d", minTrainingListings, len(clean))
+	}
+
+	// Deterministic split: every k-th listing by id is held out
+	sort.Slice(clean, func(i, j int) bool { return clean[i].ID < clean[j].ID })
+	every := int(math.Round(1 / s.cfg.TestFraction))
+	if every < 2 {
+		every = 2
+	}
+	if every > len(clean) {
+		every = len(clean)
+	}
+	var train, test []models.Listing
+	for i, listing := range clean {
+		if i%every == every-1 {
+			test = append(test, listing)
+		} else {
+			train = append(train, listing)
+		}
+	}
+
+	evalModel := TrainPriceModel(train, s.cfg.Lambda, s.cfg.MinCategoryCount)
+	mae, r2 := evalModel.evaluate(test)
+
+	model := TrainPriceModel(clean, s.cfg.Lambda, s.cfg.MinCategoryCount)
+	run := &models.ModelRun{
+		Algorithm:   "ridge",
+		Samples:     len(clean),
+		TestSamples: len(test),
+		Features:    model.featureCount() - 1,
+		Lambda:      s.cfg.Lambda,
+		MAE:         mae,
+		R2:          r2,
+	}
+	if err := s.db.InsertModelRun(run); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return model, priced, run, nil
+}
+
+// GetPricePredictions trains the model and returns every priced listing with its expected price,
+// most under-priced first
+func (s *FairPriceService) GetPricePredictions() ([]PricePrediction, *models.ModelRun, error) {
+	model, listings, run, err := s.Train()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	predictions := make([]PricePrediction, 0, len(listings))
+	for i := range listings {
+		predicted := model.Predict(&listings[i])
+		predictions = append(predictions, PricePrediction{
+			Listing:   listings[i],
+			Location:  cityOf(&listings[i]),
+			Predicted: predicted,
+			Residual:  (listings[i].Price - predicted) / predicted,
+		})
+	}
+
+	sort.Slice(predictions, func(i, j int) bool { return predictions[i].Residual < predictions[j].Residual })
+	return predictions, run, nil
+}
+
+// PrintFairPrice trains the model, prints its metrics and the n listings priced furthest
+// below and above their prediction
+func (s *FairPriceService) PrintFairPrice(n int) error {
+	predictions, run, err := s.GetPricePredictions()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("\n FAIR-PRICE MODEL (ridge, λ=%.2f):", run.Lambda)
+	s.logger.Info("   Trained on:  %d listings, %d features", run.Samples, run.Features)
+	s.logger.Info("   Held out:    %d listings", run.TestSamples)
+	s.logger.Info("   MAE:         $%.2f", run.MAE)
+	s.logger.Info("   R²:          %.3f", run.R2)
+
+	history, err := s.db.GetRecentModelRuns(5)
+	if err == nil && len(history) > 1 {
+		s.logger.Info("\n   Previous runs:")
+		for _, h := range history[1:] {
+			s.logger.Info("   %s  MAE $%.2f  R² %.3f  (%d listings)",
+				h.TrainedAt.Format("2006-01-02 15:04"), h.MAE, h.R2, h.Samples)
+		}
+	}
+
+	if len(predictions) < n {
+		n = len(predictions)
+	}
+
+	s.logger.Info("\n💸 MOST UNDER-PRICED vs MODEL:")
+	for i := 0; i < n; i++ {
+		s.printPrediction(i+1, &predictions[i])
+	}
+
+	s.logger.Info("\n💎 MOST OVER-PRICED vs MODEL:")
+	for i := 0; i < n; i++ {
+		s.printPrediction(i+1, &predictions[len(predictions)-1-i])
+	}
+	s.logger.Info("")
+	return nil
+}
+
+// printPrediction prints one listing with its actual and expected price
+func (s *FairPriceService) printPrediction(rank int, p *PricePrediction) {
+	s.logger.Info("\n   %d. %s", rank, p.Listing.Title)
+	s.logger.Info("      Price: $%.2f | Expected: $%.2f (%+.0f%%) | Location: %s",
+		p.Listing.Price, p.Predicted, p.Residual*100, p.Location)
+	s.logger.Info("      %d bedrooms, %d bathrooms, %d guests | Rating: %.2f (%d reviews)",
+		p.Listing.Bedrooms, p.Listing.Bathrooms, p.Listing.Guests, p.Listing.Rating, p.Listing.ReviewCount)
+	s.logger.Info("      URL: %s", p.Listing.URL)
+}
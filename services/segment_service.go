@@ -0,0 +1,475 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// SegmentService clusters listings into market segments with k-means and stores the assignment
+type SegmentService struct {
+	db       *storage.DB
+	logger   *utils.Logger
+	cfg      *config.SegmentsConfig
+	detector *QualityDetector
+}
+
+// Segment is one market segment and the average listing in it
+type Segment struct {
+	ID       int
+	Label    string // e.g. "Budget compact studios"
+	Size     int
+	Centroid SegmentCentroid
+}
+
+// SegmentCentroid describes the listings of a segment in original units
+// Rating and amenities average the listings where they are known
+type SegmentCentroid struct {
+	Price        float64
+	Guests       float64
+	Bedrooms     float64
+	Rating       float64
+	Amenities    float64
+	PropertyType string // most common property type
+}
+
+// LocationSegments breaks the segments down for one location
+type LocationSegments struct {
+	Location string
+	Total    int
+	Segments []Segment // sizes and centroids of the location's listings only
+}
+
+// NewSegmentService creates a new segment service
+// Segmentation leaves out listings flagged by the data quality checks
+func NewSegmentService(db *storage.DB, logger *utils.Logger, cfg *config.SegmentsConfig, analyticsCfg *config.AnalyticsConfig) *SegmentService {
+	return &SegmentService{
+		db:       db,
+		logger:   logger,
+		cfg:      cfg,
+		detector: NewQualityDetector(analyticsCfg),
+	}
+}
+
+// segmentPoints builds the normalized feature vectors of the listings:
+// log price, capacity (guests, or two per bedroom), rating and amenity count, standardized,
+// with unknown values imputed by the mean, followed by the one-hot property type scaled so that
+// two different types are one standard deviation apart
+func segmentPoints(listings []models.Listing, minCategoryCount int) [][]float64 {
+	const numeric = 4
+	raw := make([][]float64, len(listings))
+	known := make([][]bool, len(listings))
+	types := make([]string, len(listings))
+
+	for i := range listings {
+		l := &listings[i]
+		capacity := float64(l.Guests)
+		if capacity == 0 {
+			capacity = float64(2 * l.Bedrooms)
+		}
+		raw[i] = []float64{math.Log(l.Price), capacity, l.Rating, float64(l.Amenities)}
+		known[i] = []bool{true, capacity > 0, l.Rating > 0, l.Amenities > 0}
+		types[i] = propertyTypeOf(l)
+	}
+
+	columns := categoryColumns(types, minCategoryCount, numeric)
+	points := make([][]float64, len(listings))
+	for i := range points {
+		points[i] = make([]float64, numeric+len(columns))
+	}
+
+	for j := 0; j < numeric; j++ {
+		values := []float64{}
+		for i := range raw {
+			if known[i][j] {
+				values = append(values, raw[i][j])
+			}
+		}
+		m := mean(values)
+		sd := stdDev(values, m)
+		for i := range raw {
+			if known[i][j] && sd > 0 {
+				points[i][j] = (raw[i][j] - m) / sd
+			}
+		}
+	}
+
+	for i, t := range types {
+		if column, ok := columns[t]; ok {
+			points[i][column] = 1 / math.Sqrt2
+		}
+	}
+	return points
+}
+
+// kMeans clusters points into k groups, starting from k-means++ seeds
+// Returns the cluster of each point and the total squared distance to the centroids
+func kMeans(points [][]float64, k, maxIterations int, rng *rand.Rand) ([]int, float64) {
+	centroids := [][]float64{append([]float64(nil), points[rng.Intn(len(points))]...)}
+	distances := make([]float64, len(points))
+	for len(centroids) < k {
+		var total float64
+		for i, p := range points {
+			distances[i] = math.Inf(1)
+			for _, c := range centroids {
+				distances[i] = math.Min(distances[i], squaredDistance(p, c))
+			}
+			total += distances[i]
+		}
+		if total == 0 {
+			break // fewer distinct points than k
+		}
+		target := rng.Float64() * total
+		next := len(points) - 1
+		for i, d := range distances {
+			if target -= d; target <= 0 {
+				next = i
+				break
+			}
+		}
+		centroids = append(centroids, append([]float64(nil), points[next]...))
+	}
+
+	assignments := make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+
+	var inertia float64
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		changed := false
+		inertia = 0
+		for i, p := range points {
+			best, bestDistance := 0, math.Inf(1)
+			for c, centroid := range centroids {
+				if d := squaredDistance(p, centroid); d < bestDistance {
+					best, bestDistance = c, d
+				}
+			}
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+			inertia += bestDistance
+		}
+		if !changed {
+			break
+		}
+
+		counts := make([]int, len(centroids))
+		for c := range centroids {
+			for j := range centroids[c] {
+				centroids[c][j] = 0
+			}
+		}
+		for i, p := range points {
+			c := assignments[i]
+			counts[c]++
+			for j, v := range p {
+				centroids[c][j] += v
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				continue
+			}
+			for j := range centroids[c] {
+				centroids[c][j] /= float64(counts[c])
+			}
+		}
+
+		// An empty cluster restarts at the point farthest from its centroid
+		for c := range centroids {
+			if counts[c] > 0 {
+				continue
+			}
+			farthest, farthestDistance := 0, -1.0
+			for i, p := range points {
+				if d := squaredDistance(p, centroids[assignments[i]]); d > farthestDistance {
+					farthest, farthestDistance = i, d
+				}
+			}
+			copy(centroids[c], points[farthest])
+		}
+	}
+
+	return assignments, inertia
+}
+
+// squaredDistance returns the squared Euclidean distance between two vectors
+func squaredDistance(a, b []float64) float64 {
+	var total float64
+	for i := range a {
+		total += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return total
+}
+
+// SegmentListings clusters priced listings into market segments
+// The best of Restarts seeded k-means runs is kept, so results are reproducible.
+// Segments are numbered by ascending average price.
+// Returns the segments and the segment id of each listing id
+func (s *SegmentService) SegmentListings(listings []models.Listing) ([]Segment, map[int]int) {
+	priced := make([]models.Listing, 0, len(listings))
+	for _, listing := range listings {
+		if listing.Price > 0 {
+			priced = append(priced, listing)
+		}
+	}
+
+	k := s.cfg.K
+	if len(priced) < k {
+		k = len(priced)
+	}
+	if k == 0 {
+		return nil, map[int]int{}
+	}
+
+	points := segmentPoints(priced, s.cfg.MinCategoryCount)
+	var best []int
+	bestInertia := math.Inf(1)
+	for restart := 0; restart < s.cfg.Restarts; restart++ {
+		assignments, inertia := kMeans(points, k, s.cfg.MaxIterations, rand.New(rand.NewSource(int64(restart+1))))
+		if inertia < bestInertia {
+			best, bestInertia = assignments, inertia
+		}
+	}
+
+	members := make(map[int][]models.Listing)
+	for i, cluster := range best {
+		members[cluster] = append(members[cluster], priced[i])
+	}
+
+	segments := make([]Segment, 0, len(members))
+	clusterOf := make([]int, 0, len(members))
+	for cluster, group := range members {
+		segments = append(segments, Segment{Size: len(group), Centroid: segmentCentroid(group)})
+		clusterOf = append(clusterOf, cluster)
+	}
+	sort.Sort(segmentsByPrice{segments, clusterOf})
+
+	prices := make([]float64, len(priced))
+	for i := range priced {
+		prices[i] = priced[i].Price
+	}
+	sort.Float64s(prices)
+
+	used := make(map[string]int)
+	idOfCluster := make(map[int]int, len(segments))
+	for i := range segments {
+		segments[i].ID = i + 1
+		idOfCluster[clusterOf[i]] = segments[i].ID
+
+		label := segmentLabel(segments[i].Centroid, prices)
+		used[label]++
+		if used[label] > 1 {
+			label = fmt.Sprintf("%s (%d)", label, used[label])
+		}
+		segments[i].Label = label
+	}
+
+	assignments := make(map[int]int, len(priced))
+	for i, cluster := range best {
+		assignments[priced[i].ID] = idOfCluster[cluster]
+	}
+	return segments, assignments
+}
+
+// segmentsByPrice sorts segments and their k-means cluster numbers by average price
+type segmentsByPrice struct {
+	segments []Segment
+	clusters []int
+}
+
+func (s segmentsByPrice) Len() int { return len(s.segments) }
+func (s segmentsByPrice) Less(i, j int) bool {
+	return s.segments[i].Centroid.Price < s.segments[j].Centroid.Price
+}
+func (s segmentsByPrice) Swap(i, j int) {
+	s.segments[i], s.segments[j] = s.segments[j], s.segments[i]
+	s.clusters[i], s.clusters[j] = s.clusters[j], s.clusters[i]
+}
+
+// segmentCentroid averages a group of listings in original units
+func segmentCentroid(group []models.Listing) SegmentCentroid {
+	var prices, guests, bedrooms, ratings, amenities []float64
+	types := make(map[string]int)
+	for i := range group {
+		l := &group[i]
+		prices = append(prices, l.Price)
+		bedrooms = append(bedrooms, float64(l.Bedrooms))
+		if l.Guests > 0 {
+			guests = append(guests, float64(l.Guests))
+		}
+		if l.Rating > 0 {
+			ratings = append(ratings, l.Rating)
+		}
+		if l.Amenities > 0 {
+			amenities = append(amenities, float64(l.Amenities))
+		}
+		types[propertyTypeOf(l)]++
+	}
+
+	return SegmentCentroid{
+		Price:        mean(prices),
+		Guests:       mean(guests),
+		Bedrooms:     mean(bedrooms),
+		Rating:       mean(ratings),
+		Amenities:    mean(amenities),
+		PropertyType: sortedByCount(types)[0],
+	}
+}
+
+// segmentLabel names a segment by price tier against all prices, capacity and property type
+func segmentLabel(c SegmentCentroid, sortedPrices []float64) string {
+	tier := "Luxury"
+	switch {
+	case c.Price < percentile(sortedPrices, 25):
+		tier = "Budget"
+	case c.Price < percentile(sortedPrices, 60):
+		tier = "Mid-range"
+	case c.Price < percentile(sortedPrices, 85):
+		tier = "Upscale"
+	}
+
+	size := "family"
+	switch {
+	case c.Guests <= 2.5:
+		size = "compact"
+	case c.Guests <= 4.5:
+		size = "mid-size"
+	}
+
+	kind := "stays"
+	if c.PropertyType != "Unknown" {
+		kind = pluralize(strings.ToLower(c.PropertyType))
+	}
+	return fmt.Sprintf("%s %s %s", tier, size, kind)
+}
+
+// pluralize returns the English plural of a lowercase noun phrase
+func pluralize(noun string) string {
+	switch {
+	case strings.HasSuffix(noun, "s"), strings.HasSuffix(noun, "x"),
+		strings.HasSuffix(noun, "ch"), strings.HasSuffix(noun, "sh"):
+		return noun + "es"
+	case len(noun) > 1 && strings.HasSuffix(noun, "y") && !strings.ContainsAny(noun[len(noun)-2:len(noun)-1], "aeiou"):
+		return noun[:len(noun)-1] + "ies"
+	}
+	return noun + "s"
+}
+
+// FindSegments segments the listings left after the data quality checks and replaces the stored assignment
+func (s *SegmentService) FindSegments() ([]Segment, []LocationSegments, error) {
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+	listings, _ = s.detector.Filter(listings)
+
+	segments, assignments := s.SegmentListings(listings)
+
+	labels := make(map[int]string, len(segments))
+	for _, segment := range segments {
+		labels[segment.ID] = segment.Label
+	}
+	if err := s.db.ReplaceSegments(assignments, labels); err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("Assigned %d listings to %d market segments", len(assignments), len(segments))
+	return segments, segmentsByLocation(listings, segments, assignments), nil
+}
+
+// segmentsByLocation computes the size and centroid of each segment within each location
+func segmentsByLocation(listings []models.Listing, segments []Segment, assignments map[int]int) []LocationSegments {
+	groups := make(map[string]map[int][]models.Listing)
+	for _, listing := range listings {
+		id, ok := assignments[listing.ID]
+		if !ok {
+			continue
+		}
+		location := cityOf(&listing)
+		if groups[location] == nil {
+			groups[location] = make(map[int][]models.Listing)
+		}
+		groups[location][id] = append(groups[location][id], listing)
+	}
+
+	result := make([]LocationSegments, 0, len(groups))
+	for location, bySegment := range groups {
+		ls := LocationSegments{Location: location}
+		for _, segment := range segments {
+			group, ok := bySegment[segment.ID]
+			if !ok {
+				continue
+			}
+			ls.Total += len(group)
+			ls.Segments = append(ls.Segments, Segment{
+				ID:       segment.ID,
+				Label:    segment.Label,
+				Size:     len(group),
+				Centroid: segmentCentroid(group),
+			})
+		}
+		result = append(result, ls)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Location < result[j].Location
+	})
+	return result
+}
+
+// PrintSegments segments the listings and prints segment sizes and centroids overall and per location
+func (s *SegmentService) PrintSegments() error {
+	segments, locations, err := s.FindSegments()
+	if err != nil {
+		return err
+	}
+
+	if len(segments) == 0 {
+		s.logger.Info("\nNo priced listings to segment\n")
+		return nil
+	}
+
+	s.logger.Info("\n🧩 MARKET SEGMENTS (k-means, k=%d):", len(segments))
+	s.printSegmentTable(segments)
+
+	for _, location := range locations {
+		s.logger.Info("\n   %s (%d listings)", location.Location, location.Total)
+		s.printSegmentTable(location.Segments)
+	}
+	s.logger.Info("")
+	return nil
+}
+
+// printSegmentTable prints one row per segment
+func (s *SegmentService) printSegmentTable(segments []Segment) {
+	width := utf8.RuneCountInString("Segment")
+	for _, segment := range segments {
+		if n := utf8.RuneCountInString(segment.Label); n > width {
+			width = n
+		}
+	}
+
+	s.logger.Info("   %-*s %6s %10s %7s %9s %7s %10s  %s",
+		width, "Segment", "Size", "Price", "Guests", "Bedrooms", "Rating", "Amenities", "Type")
+	s.logger.Info("   %s", strings.Repeat("-", width+72))
+	for _, segment := range segments {
+		c := segment.Centroid
+		s.logger.Info("   %-*s %6d %10.2f %7.1f %9.1f %7.2f %10.1f  %s",
+			width, segment.Label, segment.Size, c.Price, c.Guests, c.Bedrooms, c.Rating, c.Amenities, c.PropertyType)
+	}
+}
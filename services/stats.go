@@ -0,0 +1,125 @@
+package services
+
+import (
+	"math"
+	"sort"
+)
+
+// PriceDistribution summarizes the spread of nightly prices
+type PriceDistribution struct {
+	Count     int
+	Mean      float64
+	Median    float64
+	P10       float64
+	P25       float64
+	P75       float64
+	P90       float64
+	StdDev    float64
+	IQR       float64
+	Min       float64
+	Max       float64
+	Histogram []HistogramBucket
+}
+
+// HistogramBucket counts prices in [Low, High); the last bucket has High = +Inf
+type HistogramBucket struct {
+	Low   float64
+	High  float64
+	Count int
+}
+
+// newPriceDistribution computes distribution statistics for a set of prices
+// bounds are the ascending lower edges of the histogram buckets
+func newPriceDistribution(prices []float64, bounds []float64) *PriceDistribution {
+	dist := &PriceDistribution{Count: len(prices)}
+	if len(prices) == 0 {
+		return dist
+	}
+
+	sorted := make([]float64, len(prices))
+	copy(sorted, prices)
+	sort.Float64s(sorted)
+
+	dist.Min = sorted[0]
+	dist.Max = sorted[len(sorted)-1]
+	dist.Mean = mean(sorted)
+	dist.Median = percentile(sorted, 50)
+	dist.P10 = percentile(sorted, 10)
+	dist.P25 = percentile(sorted, 25)
+	dist.P75 = percentile(sorted, 75)
+	dist.P90 = percentile(sorted, 90)
+	dist.IQR = dist.P75 - dist.P25
+	dist.StdDev = stdDev(sorted, dist.Mean)
+	dist.Histogram = histogram(sorted, bounds)
+
+	return dist
+}
+
+// mean returns the arithmetic mean, or 0 for no values
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// percentile returns the p-th percentile (0-100) of sorted values using linear interpolation
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
+
+// stdDev returns the population standard deviation
+func stdDev(values []float64, mean float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var sumSquares float64
+	for _, v := range values {
+		sumSquares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sumSquares / float64(len(values)))
+}
+
+// histogram counts sorted values into buckets starting at each bound
+// Values below the first bound are counted in the first bucket
+func histogram(sorted []float64, bounds []float64) []HistogramBucket {
+	if len(bounds) == 0 {
+		return nil
+	}
+
+	buckets := make([]HistogramBucket, len(bounds))
+	for i, low := range bounds {
+		buckets[i] = HistogramBucket{Low: low, High: math.Inf(1)}
+		if i+1 < len(bounds) {
+			buckets[i].High = bounds[i+1]
+		}
+	}
+
+	for _, v := range sorted {
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > v }) - 1
+		if i < 0 {
+			i = 0
+		}
+		buckets[i].Count++
+	}
+
+	return buckets
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/config"
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// SweepService plans date sweeps and reports weekend premiums, seasonality and
+// length-of-stay discounts from the stored price quotes
+type SweepService struct {
+	db     *storage.DB
+	logger *utils.Logger
+	cfg    *config.PriceSweepConfig
+}
+
+// SweepSummary holds the price patterns of the quoted listings of one location
+// Percentages are NaN when the quotes do not cover the comparison
+type SweepSummary struct {
+	Location       string
+	Listings       int
+	Quotes         int
+	Unavailable    int
+	WeekendPremium float64         // Fri/Sat check-ins vs Sun-Thu check-ins, percent
+	LOSDiscounts   map[int]float64 // nights -> nightly price change vs the shortest stay, percent
+	Seasonality    []MonthIndex
+}
+
+// MonthIndex is the median nightly price of one check-in month relative to each listing's median, percent
+type MonthIndex struct {
+	Month  string // YYYY-MM
+	Change float64
+}
+
+// NewSweepService creates a new sweep service
+func NewSweepService(db *storage.DB, logger *utils.Logger, cfg *config.PriceSweepConfig) *SweepService {
+	return &SweepService{
+		db:     db,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// SelectListings resolves a sweep target to stored listings
+// The target is a comma-separated list of listing URLs/room IDs, or a location name,
+// in which case its MaxListings most reviewed listings are swept
+func (s *SweepService) SelectListings(target string) ([]models.Listing, error) {
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+
+	ids := []string{}
+	for _, item := range strings.Split(target, ",") {
+		id := roomIDOf(item)
+		if id == "" {
+			ids = nil
+			break
+		}
+		ids = append(ids, id)
+	}
+
+	selected := []models.Listing{}
+	if ids != nil {
+		wanted := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			wanted[id] = true
+		}
+		for _, listing := range listings {
+			if wanted[listing.ListingID] {
+				selected = append(selected, listing)
+				delete(wanted, listing.ListingID)
+			}
+		}
+		for id := range wanted {
+			s.logger.Warning("Listing %s is not stored yet; scrape it first", id)
+		}
+		return selected, nil
+	}
+
+	for _, listing := range listings {
+		if strings.EqualFold(cityOf(&listing), target) || strings.Contains(strings.ToLower(listing.Location), strings.ToLower(target)) {
+			selected = append(selected, listing)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].ReviewCount > selected[j].ReviewCount })
+	if len(selected) > s.cfg.MaxListings {
+		selected = selected[:s.cfg.MaxListings]
+	}
+	return selected, nil
+}
+
+// roomIDOf returns the Airbnb room ID of a listing URL or a bare room ID, empty for anything else
+func roomIDOf(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if id := utils.ExtractListingID(ref); id != "" {
+		return id
+	}
+	if ref != "" && strings.Trim(ref, "0123456789") == "" {
+		return ref
+	}
+	return ""
+}
+
+// CheckInDates returns the configured check-in weekdays from tomorrow over the next Weeks weeks
+func (s *SweepService) CheckInDates(today time.Time) []time.Time {
+	weekdays, _ := s.cfg.Weekdays() // validated when the config is loaded
+	wanted := make(map[time.Weekday]bool, len(weekdays))
+	for _, day := range weekdays {
+		wanted[day] = true
+	}
+
+	start := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{}
+	for offset := 1; offset <= s.cfg.Weeks*7; offset++ {
+		date := start.AddDate(0, 0, offset)
+		if wanted[date.Weekday()] {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// SaveQuotes stores the quotes of a sweep
+func (s *SweepService) SaveQuotes(quotes []models.PriceQuote) error {
+	if err := s.db.InsertPriceQuotes(quotes); err != nil {
+		return err
+	}
+
+	available := 0
+	for _, q := range quotes {
+		if q.Available {
+			available++
+		}
+	}
+	s.logger.Success("Saved %d price quotes (%d bookable)", len(quotes), available)
+	return nil
+}
+
+// GetSweepSummaries summarizes the latest quotes overall and per location
+// The first summary covers all locations
+func (s *SweepService) GetSweepSummaries() ([]SweepSummary, error) {
+	quotes, err := s.db.GetLatestPriceQuotes()
+	if err != nil {
+		return nil, err
+	}
+
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listings: %w", err)
+	}
+	locations := make(map[int]string, len(listings))
+	for i := range listings {
+		locations[listings[i].ID] = cityOf(&listings[i])
+	}
+
+	if len(quotes) == 0 {
+		return nil, nil
+	}
+
+	groups := make(map[string][]models.PriceQuote)
+	for _, q := range quotes {
+		location, ok := locations[q.ListingID]
+		if !ok {
+			continue
+		}
+		groups[location] = append(groups[location], q)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	summaries := []SweepSummary{summarizeQuotes("All locations", quotes)}
+	for _, name := range names {
+		summaries = append(summaries, summarizeQuotes(name, groups[name]))
+	}
+	return summaries, nil
+}
+
+// summarizeQuotes measures the price patterns of a set of quotes
+// Every comparison is made within a listing first and the per-listing results are summarized by their median:
+//   - weekend premium: shortest-stay nightly price of Fri/Sat check-ins vs Sun-Thu check-ins
+//   - length-of-stay discount: nightly price of each stay length vs the shortest stay on the same check-in date
+//   - seasonality: shortest-stay nightly price per check-in month vs the listing's median
+//
+// Fee-inclusive totals and nightly-rate totals are never compared with each other: a listing's quotes
+// are split by price basis and each basis is compared on its own.
+func summarizeQuotes(location string, quotes []models.PriceQuote) SweepSummary {
+	summary := SweepSummary{
+		Location:       location,
+		Quotes:         len(quotes),
+		WeekendPremium: math.NaN(),
+		LOSDiscounts:   make(map[int]float64),
+	}
+
+	type quoteGroup struct {
+		listingID int
+		basis     string
+	}
+	byListing := make(map[quoteGroup][]models.PriceQuote)
+	listingIDs := make(map[int]bool)
+	for _, q := range quotes {
+		if !q.Available || q.NightlyPrice <= 0 {
+			summary.Unavailable++
+			continue
+		}
+		group := quoteGroup{listingID: q.ListingID, basis: q.PriceBasis}
+		byListing[group] = append(byListing[group], q)
+		listingIDs[q.ListingID] = true
+	}
+	summary.Listings = len(listingIDs)
+
+	premiums := []float64{}
+	discounts := make(map[int][]float64)
+	months := make(map[string][]float64)
+
+	for _, listingQuotes := range byListing {
+		shortest := listingQuotes[0].Nights
+		for _, q := range listingQuotes {
+			if q.Nights < shortest {
+				shortest = q.Nights
+			}
+		}
+
+		base := make(map[string]float64) // check-in date -> shortest-stay nightly price
+		var weekend, weekday, all []float64
+		for _, q := range listingQuotes {
+			if q.Nights != shortest {
+				continue
+			}
+			base[q.CheckIn.Format("2006-01-02")] = q.NightlyPrice
+			all = append(all, q.NightlyPrice)
+			if day := q.CheckIn.Weekday(); day == time.Friday || day == time.Saturday {
+				weekend = append(weekend, q.NightlyPrice)
+			} else {
+				weekday = append(weekday, q.NightlyPrice)
+			}
+		}
+
+		if len(weekend) > 0 && len(weekday) > 0 {
+			premiums = append(premiums, percentChange(mean(weekday), mean(weekend)))
+		}
+
+		for _, q := range listingQuotes {
+			if q.Nights == shortest {
+				continue
+			}
+			if price, ok := base[q.CheckIn.Format("2006-01-02")]; ok {
+				discounts[q.Nights] = append(discounts[q.Nights], percentChange(price, q.NightlyPrice))
+			}
+		}
+
+		sort.Float64s(all)
+		median := percentile(all, 50)
+		for _, q := range listingQuotes {
+			if q.Nights == shortest {
+				month := q.CheckIn.Format("2006-01")
+				months[month] = append(months[month], percentChange(median, q.NightlyPrice))
+			}
+		}
+	}
+
+	if len(premiums) > 0 {
+		summary.WeekendPremium = medianOf(premiums)
+	}
+	for nights, changes := range discounts {
+		summary.LOSDiscounts[nights] = medianOf(changes)
+	}
+	for month, changes := range months {
+		summary.Seasonality = append(summary.Seasonality, MonthIndex{Month: month, Change: medianOf(changes)})
+	}
+	sort.Slice(summary.Seasonality, func(i, j int) bool { return summary.Seasonality[i].Month < summary.Seasonality[j].Month })
+
+	return summary
+}
+
+// medianOf returns the median of unsorted values
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return percentile(sorted, 50)
+}
+
+// PrintSweepReport prints weekend premiums, length-of-stay discounts and seasonality per location
+func (s *SweepService) PrintSweepReport() error {
+	summaries, err := s.GetSweepSummaries()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("\n📅 DATE SWEEP PRICING (latest quote per listing, check-in and stay length):")
+	if len(summaries) == 0 {
+		s.logger.Info("   No upcoming price quotes; run --price-sweep first\n")
+		return nil
+	}
+
+	for _, summary := range summaries {
+		s.logger.Info("\n   %s (%d listings, %d quotes, %d not bookable)",
+			summary.Location, summary.Listings, summary.Quotes, summary.Unavailable)
+		s.logger.Info("   %s", strings.Repeat("-", 60))
+		s.logger.Info("   Weekend premium:  %s (Fri/Sat vs Sun-Thu check-ins)", formatPercent(summary.WeekendPremium))
+
+		nights := make([]int, 0, len(summary.LOSDiscounts))
+		for n := range summary.LOSDiscounts {
+			nights = append(nights, n)
+		}
+		sort.Ints(nights)
+		stays := make([]string, 0, len(nights))
+		for _, n := range nights {
+			stays = append(stays, fmt.Sprintf("%d nights %s", n, formatPercent(summary.LOSDiscounts[n])))
+		}
+		if len(stays) == 0 {
+			stays = append(stays, "-")
+		}
+		s.logger.Info("   Length of stay:   %s (nightly price vs shortest stay)", strings.Join(stays, ", "))
+
+		s.logger.Info("   Seasonality (nightly price vs listing median, by check-in month):")
+		for _, month := range summary.Seasonality {
+			s.logger.Info("      %s  %s", month.Month, formatPercent(month.Change))
+		}
+	}
+	s.logger.Info("")
+	return nil
+}
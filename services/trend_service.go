@@ -0,0 +1,352 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// TrendService handles price history analytics across scrape runs
+type TrendService struct {
+	db     *storage.DB
+	logger *utils.Logger
+}
+
+// TrendPoint is the price level of a location on one day
+type TrendPoint struct {
+	Date   time.Time
+	Median float64
+	Mean   float64
+	Count  int
+}
+
+// LocationTrend compares the median price of a location with earlier periods
+type LocationTrend struct {
+	Location      string
+	CurrentMedian float64
+	WeekAgo       float64 // median of the previous 7-day window, 0 if no data
+	MonthAgo      float64 // median of the previous 30-day window, 0 if no data
+	WoWChange     float64 // percent, NaN if no data
+	MoMChange     float64 // percent, NaN if no data
+}
+
+// PriceMove is the price change of one listing over a period
+type PriceMove struct {
+	ListingID  int
+	Title      string
+	Location   string
+	URL        string
+	FirstPrice float64
+	LastPrice  float64
+	Change     float64
+	ChangePct  float64
+	From       time.Time
+	To         time.Time
+}
+
+// NewTrendService creates a new trend service
+func NewTrendService(db *storage.DB, logger *utils.Logger) *TrendService {
+	return &TrendService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// snapshotLocation returns the location a snapshot is grouped under, like cityOf for listings
+func snapshotLocation(s *models.ListingSnapshot) string {
+	if s.CanonicalLocation != "" {
+		return s.CanonicalLocation
+	}
+	if s.City != "" {
+		return s.City
+	}
+	return s.Location
+}
+
+// latestPerListing keeps the last priced snapshot of each listing captured in [from, to)
+// snapshots must be ordered by capture time
+func latestPerListing(snapshots []models.ListingSnapshot, from, to time.Time) map[int]*models.ListingSnapshot {
+	latest := make(map[int]*models.ListingSnapshot)
+	for i := range snapshots {
+		s := &snapshots[i]
+		if s.Price <= 0 || s.CapturedAt.Before(from) || !s.CapturedAt.Before(to) {
+			continue
+		}
+		latest[s.ListingID] = s
+	}
+	return latest
+}
+
+// medianByLocation computes the median price per location of one snapshot per listing
+func medianByLocation(latest map[int]*models.ListingSnapshot) map[string]float64 {
+	prices := make(map[string][]float64)
+	for _, s := range latest {
+		location := snapshotLocation(s)
+		prices[location] = append(prices[location], s.Price)
+	}
+
+	medians := make(map[string]float64, len(prices))
+	for location, values := range prices {
+		sort.Float64s(values)
+		medians[location] = percentile(values, 50)
+	}
+	return medians
+}
+
+// percentChange returns the change from previous to current in percent, NaN if previous is 0
+func percentChange(previous, current float64) float64 {
+	if previous == 0 {
+		return math.NaN()
+	}
+	return (current - previous) / previous * 100
+}
+
+// GetLocationTrends returns week-over-week and month-over-month median price changes per location
+// The current median uses the last 7 days; it is compared with the 7 days and the 30 days before
+func (s *TrendService) GetLocationTrends() ([]LocationTrend, error) {
+	now := time.Now()
+	weekAgo := now.AddDate(0, 0, -7)
+	twoWeeksAgo := now.AddDate(0, 0, -14)
+	monthAgo := now.AddDate(0, 0, -30)
+	twoMonthsAgo := now.AddDate(0, 0, -60)
+
+	snapshots, err := s.db.GetSnapshotsSince(twoMonthsAgo)
+	if err != nil {
+		return nil, err
+	}
+
+	current := medianByLocation(latestPerListing(snapshots, weekAgo, now.Add(time.Second)))
+	lastWeek := medianByLocation(latestPerListing(snapshots, twoWeeksAgo, weekAgo))
+	lastMonth := medianByLocation(latestPerListing(snapshots, twoMonthsAgo, monthAgo))
+
+	trends := make([]LocationTrend, 0, len(current))
+	for location, median := range current {
+		trend := LocationTrend{
+			Location:      location,
+			CurrentMedian: median,
+			WeekAgo:       lastWeek[location],
+			MonthAgo:      lastMonth[location],
+		}
+		trend.WoWChange = percentChange(trend.WeekAgo, median)
+		trend.MoMChange = percentChange(trend.MonthAgo, median)
+		trends = append(trends, trend)
+	}
+
+	sort.Slice(trends, func(i, j int) bool { return trends[i].Location < trends[j].Location })
+	return trends, nil
+}
+
+// GetLocationSeries returns the daily median price per location over the last days
+// Each listing contributes its last snapshot of the day
+func (s *TrendService) GetLocationSeries(days int) (map[string][]TrendPoint, error) {
+	snapshots, err := s.db.GetSnapshotsSince(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	type dayKey struct {
+		location string
+		day      time.Time
+	}
+
+	// Last snapshot per listing per day
+	latest := make(map[dayKey]map[int]float64)
+	for i := range snapshots {
+		snap := &snapshots[i]
+		if snap.Price <= 0 {
+			continue
+		}
+
+		key := dayKey{location: snapshotLocation(snap), day: snap.CapturedAt.Truncate(24 * time.Hour)}
+		if latest[key] == nil {
+			latest[key] = make(map[int]float64)
+		}
+		latest[key][snap.ListingID] = snap.Price
+	}
+
+	series := make(map[string][]TrendPoint)
+	for key, byListing := range latest {
+		prices := make([]float64, 0, len(byListing))
+		for _, price := range byListing {
+			prices = append(prices, price)
+		}
+		sort.Float64s(prices)
+
+		series[key.location] = append(series[key.location], TrendPoint{
+			Date:   key.day,
+			Median: percentile(prices, 50),
+			Mean:   mean(prices),
+			Count:  len(prices),
+		})
+	}
+
+	for location := range series {
+		points := series[location]
+		sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	}
+
+	return series, nil
+}
+
+// GetTopMovers returns the n listings with the largest relative price change over the last days
+func (s *TrendService) GetTopMovers(days, n int) ([]PriceMove, error) {
+	snapshots, err := s.db.GetSnapshotsSince(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	first := make(map[int]*models.ListingSnapshot)
+	last := make(map[int]*models.ListingSnapshot)
+	for i := range snapshots {
+		snap := &snapshots[i]
+		if snap.Price <= 0 {
+			continue
+		}
+		if _, ok := first[snap.ListingID]; !ok {
+			first[snap.ListingID] = snap
+		}
+		last[snap.ListingID] = snap
+	}
+
+	moves := []PriceMove{}
+	for listingID, start := range first {
+		end := last[listingID]
+		if end.Price == start.Price {
+			continue
+		}
+
+		moves = append(moves, PriceMove{
+			ListingID:  listingID,
+			Title:      end.Title,
+			Location:   snapshotLocation(end),
+			URL:        end.URL,
+			FirstPrice: start.Price,
+			LastPrice:  end.Price,
+			Change:     end.Price - start.Price,
+			ChangePct:  percentChange(start.Price, end.Price),
+			From:       start.CapturedAt,
+			To:         end.CapturedAt,
+		})
+	}
+
+	sort.Slice(moves, func(i, j int) bool {
+		return math.Abs(moves[i].ChangePct) > math.Abs(moves[j].ChangePct)
+	})
+
+	if len(moves) > n {
+		moves = moves[:n]
+	}
+	return moves, nil
+}
+
+// PrintTrends prints median price changes per location and the largest listing price moves
+func (s *TrendService) PrintTrends(days, n int) error {
+	trends, err := s.GetLocationTrends()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("\n📈 MEDIAN PRICE TREND BY LOCATION (last 7 days vs earlier):")
+	if len(trends) == 0 {
+		s.logger.Info("   No snapshots in the last 7 days")
+	} else {
+		s.logger.Info("   %-35s %10s %10s %8s %10s %8s", "Location", "Median", "Week ago", "WoW", "Month ago", "MoM")
+		for _, trend := range trends {
+			s.logger.Info("   %-35s %10.2f %10s %8s %10s %8s",
+				trend.Location, trend.CurrentMedian,
+				formatPrice(trend.WeekAgo), formatPercent(trend.WoWChange),
+				formatPrice(trend.MonthAgo), formatPercent(trend.MoMChange))
+		}
+	}
+
+	moves, err := s.GetTopMovers(days, n)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("\n LARGEST PRICE MOVES (last %d days):", days)
+	if len(moves) == 0 {
+		s.logger.Info("   No price changes recorded")
+	}
+	for i, move := range moves {
+		s.logger.Info("\n   %d. %s", i+1, move.Title)
+		s.logger.Info("      $%.2f → $%.2f (%+.2f, %s) | %s → %s",
+			move.FirstPrice, move.LastPrice, move.Change, formatPercent(move.ChangePct),
+			move.From.Format("2006-01-02"), move.To.Format("2006-01-02"))
+		s.logger.Info("      Location: %s | URL: %s", move.Location, move.URL)
+	}
+	s.logger.Info("")
+	return nil
+}
+
+// ExportSeriesCSV writes the daily median price per location to a CSV file
+func (s *TrendService) ExportSeriesCSV(filename string, days int) error {
+	s.logger.Info("Exporting price series to CSV: %s", filename)
+
+	series, err := s.GetLocationSeries(days)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"Date", "Location", "Median Price", "Mean Price", "Listings"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	locations := make([]string, 0, len(series))
+	for location := range series {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	rows := 0
+	for _, location := range locations {
+		for _, point := range series[location] {
+			row := []string{
+				point.Date.Format("2006-01-02"),
+				location,
+				fmt.Sprintf("%.2f", point.Median),
+				fmt.Sprintf("%.2f", point.Mean),
+				fmt.Sprintf("%d", point.Count),
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+			rows++
+		}
+	}
+
+	s.logger.Success("Exported %d daily points for %d locations to %s", rows, len(locations), filename)
+	return nil
+}
+
+// formatPrice formats a price, or "-" when there is no data
+func formatPrice(price float64) string {
+	if price == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", price)
+}
+
+// formatPercent formats a percent change with sign, or "-" when it is undefined
+func formatPercent(pct float64) string {
+	if math.IsNaN(pct) {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", pct)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// ValueMetrics relates a listing's price to its capacity and its market
+type ValueMetrics struct {
+	Listing         models.Listing
+	Location        string
+	PricePerGuest   float64
+	PricePerBedroom float64 // studios count as one bedroom
+	RelativePrice   float64 // price / location median price
+	ValueScore      float64 // 100 = location-typical rating at the location-median price per guest
+}
+
+// GetValueMetrics computes value metrics for every listing with a price and guest capacity
+func (s *AnalyticsService) GetValueMetrics() ([]ValueMetrics, error) {
+	listings, _, err := s.loadListings()
+	if err != nil {
+		return nil, err
+	}
+
+	return computeValueMetrics(listings), nil
+}
+
+// computeValueMetrics derives per-listing value metrics.
+// The value score multiplies two ratios against the listing's location:
+//   - rating / location mean rating (unrated listings get the location mean)
+//   - location median price per guest / listing price per guest
+//
+// so a listing rated like its neighbours and priced at the median per guest scores 100,
+// and cheaper-per-guest or better-rated listings score higher.
+func computeValueMetrics(listings []models.Listing) []ValueMetrics {
+	type locationStats struct {
+		prices         []float64
+		pricesPerGuest []float64
+		ratingTotal    float64
+		rated          int
+	}
+
+	stats := make(map[string]*locationStats)
+	for i := range listings {
+		listing := &listings[i]
+		if listing.Price <= 0 || listing.Guests <= 0 {
+			continue
+		}
+
+		location := cityOf(listing)
+		if stats[location] == nil {
+			stats[location] = &locationStats{}
+		}
+		st := stats[location]
+
+		st.prices = append(st.prices, listing.Price)
+		st.pricesPerGuest = append(st.pricesPerGuest, listing.Price/float64(listing.Guests))
+		if listing.Rating > 0 {
+			st.ratingTotal += listing.Rating
+			st.rated++
+		}
+	}
+
+	medianPrice := make(map[string]float64)
+	medianPerGuest := make(map[string]float64)
+	meanRating := make(map[string]float64)
+	for location, st := range stats {
+		sort.Float64s(st.prices)
+		sort.Float64s(st.pricesPerGuest)
+		medianPrice[location] = percentile(st.prices, 50)
+		medianPerGuest[location] = percentile(st.pricesPerGuest, 50)
+		if st.rated > 0 {
+			meanRating[location] = st.ratingTotal / float64(st.rated)
+		}
+	}
+
+	metrics := []ValueMetrics{}
+	for i := range listings {
+		listing := listings[i]
+		if listing.Price <= 0 || listing.Guests <= 0 {
+			continue
+		}
+
+		location := cityOf(&listing)
+		bedrooms := listing.Bedrooms
+		if bedrooms < 1 {
+			bedrooms = 1
+		}
+
+		m := ValueMetrics{
+			Listing:         listing,
+			Location:        location,
+			PricePerGuest:   listing.Price / float64(listing.Guests),
+			PricePerBedroom: listing.Price / float64(bedrooms),
+			RelativePrice:   listing.Price / medianPrice[location],
+		}
+
+		ratingFactor := 1.0
+		if listing.Rating > 0 && meanRating[location] > 0 {
+			ratingFactor = listing.Rating / meanRating[location]
+		}
+		m.ValueScore = 100 * ratingFactor * medianPerGuest[location] / m.PricePerGuest
+
+		metrics = append(metrics, m)
+	}
+
+	return metrics
+}
+
+// GetBestValue returns the n highest value-score listings of each location
+func (s *AnalyticsService) GetBestValue(n int) (map[string][]ValueMetrics, error) {
+	metrics, err := s.GetValueMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].ValueScore > metrics[j].ValueScore
+	})
+
+	best := make(map[string][]ValueMetrics)
+	for _, m := range metrics {
+		if len(best[m.Location]) < n {
+			best[m.Location] = append(best[m.Location], m)
+		}
+	}
+
+	return best, nil
+}
+
+// PrintBestValue prints the top n value listings per location
+func (s *AnalyticsService) PrintBestValue(n int) error {
+	best, err := s.GetBestValue(n)
+	if err != nil {
+		return err
+	}
+
+	if len(best) == 0 {
+		s.logger.Info("\nNo listings with price and guest capacity yet\n")
+		return nil
+	}
+
+	locations := make([]string, 0, len(best))
+	for location := range best {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	s.logger.Info("\n💰 BEST VALUE PROPERTIES (top %d per location):", n)
+	for _, location := range locations {
+		s.logger.Info("\n   %s", location)
+		for i, m := range best[location] {
+			s.logger.Info("   %d. %s", i+1, m.Listing.Title)
+			s.logger.Info("      Value Score: %.0f | Price: $%.2f (%.0f%% of median) | Rating: %.2f ⭐",
+				m.ValueScore, m.Listing.Price, m.RelativePrice*100, m.Listing.Rating)
+			s.logger.Info("      $%.2f per guest (%d guests) | $%.2f per bedroom (%d bedrooms)",
+				m.PricePerGuest, m.Listing.Guests, m.PricePerBedroom, m.Listing.Bedrooms)
+		}
+	}
+	s.logger.Info("")
+	return nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+	"github.com/farhanasfar/airbnb-market-scraping-system/storage"
+	"github.com/farhanasfar/airbnb-market-scraping-system/utils"
+)
+
+// WatchlistService manages the listings refreshed by --refresh-watchlist
+type WatchlistService struct {
+	db     *storage.DB
+	logger *utils.Logger
+}
+
+// NewWatchlistService creates a new watchlist service
+func NewWatchlistService(db *storage.DB, logger *utils.Logger) *WatchlistService {
+	return &WatchlistService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Add watches the listings with the given URLs or room IDs
+// Listings do not need to be stored yet; the first refresh scrapes them
+func (s *WatchlistService) Add(refs []string, note string) error {
+	for _, ref := range refs {
+		id := roomIDOf(ref)
+		if id == "" {
+			return fmt.Errorf("%q is neither a listing URL nor a room ID", ref)
+		}
+
+		added, err := s.db.AddToWatchlist(id, utils.CanonicalListingURL(id), note)
+		if err != nil {
+			return err
+		}
+		if added {
+			s.logger.Success("Watching listing %s", id)
+		} else {
+			s.logger.Info("Listing %s is already watched", id)
+		}
+	}
+	return nil
+}
+
+// Remove stops watching the listings with the given URLs or room IDs
+func (s *WatchlistService) Remove(refs []string) error {
+	for _, ref := range refs {
+		id := roomIDOf(ref)
+		if id == "" {
+			return fmt.Errorf("%q is neither a listing URL nor a room ID", ref)
+		}
+
+		removed, err := s.db.RemoveFromWatchlist(id)
+		if err != nil {
+			return err
+		}
+		if removed {
+			s.logger.Success("Stopped watching listing %s", id)
+		} else {
+			s.logger.Warning("Listing %s is not watched", id)
+		}
+	}
+	return nil
+}
+
+// GetWatchlist returns the watched listings
+func (s *WatchlistService) GetWatchlist() ([]models.WatchedListing, error) {
+	return s.db.GetWatchlist()
+}
+
+// PrintWatchlist prints the watched listings with their last known price and refresh time
+func (s *WatchlistService) PrintWatchlist() error {
+	watched, err := s.db.GetWatchlist()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("\n👀 WATCHLIST (%d listings):", len(watched))
+	if len(watched) == 0 {
+		s.logger.Info("   No watched listings; add some with --watch-add\n")
+		return nil
+	}
+
+	s.logger.Info("\n   %-20s %-40s %10s %-16s  %s", "Listing", "Title", "Price", "Last refreshed", "Note")
+	s.logger.Info("   %s", strings.Repeat("-", 100))
+	for _, w := range watched {
+		title, price, refreshed := "(not scraped yet)", "-", "never"
+		if w.Title != "" {
+			title = w.Title
+		}
+		if w.Price > 0 {
+			price = fmt.Sprintf("%.2f", w.Price)
+		}
+		if w.LastRefreshedAt != nil {
+			refreshed = w.LastRefreshedAt.Format("2006-01-02 15:04")
+		}
+		s.logger.Info("   %-20s %-40s %10s %-16s  %s", w.ListingID, truncate(title, 40), price, refreshed, w.Note)
+	}
+	s.logger.Info("")
+	return nil
+}
+
+// CompleteRawListings fills the fields a detail page does not show the way search results do
+// (title, location, search location and rating) from the stored listing with the same room ID
+func (s *WatchlistService) CompleteRawListings(raws []models.RawListing) error {
+	listings, err := s.db.GetAllListings()
+	if err != nil {
+		return fmt.Errorf("failed to get listings: %w", err)
+	}
+
+	stored := make(map[string]*models.Listing, len(listings))
+	for i := range listings {
+		stored[listings[i].ListingID] = &listings[i]
+	}
+
+	for i := range raws {
+		listing, ok := stored[utils.ExtractListingID(raws[i].URL)]
+		if !ok {
+			continue
+		}
+		if raws[i].Title == "" {
+			raws[i].Title = listing.Title
+		}
+		if raws[i].Location == "" {
+			raws[i].Location = listing.Location
+		}
+		if raws[i].SearchLocation == "" {
+			raws[i].SearchLocation = listing.SearchLocation
+		}
+		if raws[i].Rating == "" && listing.Rating > 0 {
+			raws[i].Rating = fmt.Sprintf("%.2f (%d)", listing.Rating, listing.ReviewCount)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// AlertSent reports whether an alert was already delivered for a rule
+func (db *DB) AlertSent(ruleName, alertKey string) (bool, error) {
+	var exists bool
+	err := db.conn.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM alert_notifications WHERE rule_name = $1 AND alert_key = $2)`,
+		ruleName, alertKey,
+	).Scan(&exists)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check alert notification: %w", err)
+	}
+
+	return exists, nil
+}
+
+// RecordAlert stores a delivered alert so it is not sent again
+func (db *DB) RecordAlert(alert *models.Alert) error {
+	query := `
+		INSERT INTO alert_notifications (rule_name, alert_key, run_id, subject, message)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (rule_name, alert_key) DO NOTHING
+	`
+
+	_, err := db.conn.Exec(query, alert.Rule, alert.Key, nullableID(alert.RunID), alert.Subject, alert.Message)
+	if err != nil {
+		return fmt.Errorf("failed to record alert notification: %w", err)
+	}
+
+	return nil
+}
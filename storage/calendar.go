@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// UpsertCalendar stores the calendar nights of a listing, replacing nights scraped before
+func (db *DB) UpsertCalendar(listingID int, days []models.CalendarDay) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO listing_calendar (listing_id, date, available, price, min_nights)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (listing_id, date) DO UPDATE SET
+			available = EXCLUDED.available,
+			price = EXCLUDED.price,
+			min_nights = EXCLUDED.min_nights,
+			scraped_at = CURRENT_TIMESTAMP
+	`
+
+	for _, day := range days {
+		if _, err := tx.Exec(query, listingID, day.Date, day.Available, day.Price, day.MinNights); err != nil {
+			return fmt.Errorf("failed to save calendar night: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit calendar: %w", err)
+	}
+
+	return nil
+}
+
+// GetCalendarSummaries totals the calendar of every listing over the next days nights
+func (db *DB) GetCalendarSummaries(days int) ([]models.CalendarSummary, error) {
+	query := `
+		SELECT listing_id,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE NOT available),
+		       COALESCE(AVG(price) FILTER (WHERE NOT available AND price > 0), 0),
+		       COALESCE(AVG(price) FILTER (WHERE price > 0), 0)
+		FROM listing_calendar
+		WHERE date >= CURRENT_DATE AND date < CURRENT_DATE + $1::INTEGER
+		GROUP BY listing_id
+	`
+
+	rows, err := db.conn.Query(query, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []models.CalendarSummary
+	for rows.Next() {
+		var s models.CalendarSummary
+		if err := rows.Scan(&s.ListingID, &s.Nights, &s.BookedNights, &s.BookedPriceAvg, &s.PriceAvg); err != nil {
+			return nil, fmt.Errorf("failed to scan calendar summary: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar summaries: %w", err)
+	}
+
+	return summaries, nil
+}
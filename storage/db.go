@@ -44,6 +44,70 @@ func (db *DB) migrate() error {
 		return fmt.Errorf("failed to create listings table: %w", err)
 	}
 
+	if _, err := db.conn.Exec(MigrateListingIDSQL); err != nil {
+		return fmt.Errorf("failed to migrate listing IDs: %w", err)
+	}
+
+	if _, err := db.conn.Exec(MigratePropertyTypeSQL); err != nil {
+		return fmt.Errorf("failed to migrate property types: %w", err)
+	}
+
+	if _, err := db.conn.Exec(CreateLocationsTableSQL); err != nil {
+		return fmt.Errorf("failed to create locations table: %w", err)
+	}
+
+	if _, err := db.conn.Exec(MigrateReviewCountSQL); err != nil {
+		return fmt.Errorf("failed to migrate review counts: %w", err)
+	}
+
+	if _, err := db.conn.Exec(CreateSnapshotsTableSQL); err != nil {
+		return fmt.Errorf("failed to create snapshots tables: %w", err)
+	}
+
+	if _, err := db.conn.Exec(CreateAlertNotificationsTableSQL); err != nil {
+		return fmt.Errorf("failed to create alert notifications table: %w", err)
+	}
+
+	if _, err := db.conn.Exec(MigrateRunHealthSQL); err != nil {
+		return fmt.Errorf("failed to migrate run health: %w", err)
+	}
+
+	if _, err := db.conn.Exec(MigrateDuplicatesSQL); err != nil {
+		return fmt.Errorf("failed to migrate duplicates: %w", err)
+	}
+
+	if _, err := db.conn.Exec(CreateModelRunsTableSQL); err != nil {
+		return fmt.Errorf("failed to create model runs table: %w", err)
+	}
+
+	if _, err := db.conn.Exec(CreateListingCalendarTableSQL); err != nil {
+		return fmt.Errorf("failed to create listing calendar table: %w", err)
+	}
+
+	if _, err := db.conn.Exec(CreatePriceQuotesTableSQL); err != nil {
+		return fmt.Errorf("failed to create price quotes table: %w", err)
+	}
+
+	if _, err := db.conn.Exec(MigrateSegmentsSQL); err != nil {
+		return fmt.Errorf("failed to migrate segments: %w", err)
+	}
+
+	if _, err := db.conn.Exec(CreateWatchlistTableSQL); err != nil {
+		return fmt.Errorf("failed to create watchlist table: %w", err)
+	}
+
+	if _, err := db.conn.Exec(CreateDiscoveredLocationsTableSQL); err != nil {
+		return fmt.Errorf("failed to create discovered locations table: %w", err)
+	}
+
+	if _, err := db.conn.Exec(MigrateDetailFieldsSQL); err != nil {
+		return fmt.Errorf("failed to migrate detail fields: %w", err)
+	}
+
+	if _, err := db.conn.Exec(MigrateQuoteBasisSQL); err != nil {
+		return fmt.Errorf("failed to migrate quote basis: %w", err)
+	}
+
 	// Create triggers
 	if _, err := db.conn.Exec(UpdateUpdatedAtTriggerSQL); err != nil {
 		return fmt.Errorf("failed to create triggers: %w", err)
@@ -52,34 +116,79 @@ func (db *DB) migrate() error {
 	return nil
 }
 
-// InsertListing inserts a new listing or updates if URL already exists
-func (db *DB) InsertListing(listing *models.Listing) error {
-	query := `
-		INSERT INTO listings (title, price, location, rating, url, bedrooms, bathrooms, guests)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		ON CONFLICT (url) DO UPDATE SET
-			title = EXCLUDED.title,
-			price = EXCLUDED.price,
-			location = EXCLUDED.location,
-			rating = EXCLUDED.rating,
-			bedrooms = EXCLUDED.bedrooms,
-			bathrooms = EXCLUDED.bathrooms,
-			guests = EXCLUDED.guests,
-			updated_at = CURRENT_TIMESTAMP
-		RETURNING id
-	`
+// upsertListingSQL inserts a listing or updates the stored one
+// Capacity fields are only overwritten when the detail page read them (confidence above 0), so a
+// failed detail page never replaces known values with zeros. The stored capacity is returned.
+const upsertListingSQL = `
+	INSERT INTO listings (listing_id, title, price, location, property_type, city, search_location, rating, review_count, url,
+		bedrooms, bathrooms, guests, latitude, longitude, amenities, beds, half_baths, bathroom_type, studio,
+		guests_confidence, bedrooms_confidence, beds_confidence, bathrooms_confidence)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+		$21, $22, $23, $24)
+	ON CONFLICT (listing_id) DO UPDATE SET
+		title = EXCLUDED.title,
+		price = EXCLUDED.price,
+		location = EXCLUDED.location,
+		property_type = EXCLUDED.property_type,
+		city = EXCLUDED.city,
+		search_location = CASE WHEN EXCLUDED.search_location <> '' THEN EXCLUDED.search_location ELSE listings.search_location END,
+		rating = EXCLUDED.rating,
+		review_count = EXCLUDED.review_count,
+		url = EXCLUDED.url,
+		bedrooms = CASE WHEN EXCLUDED.bedrooms_confidence > 0 THEN EXCLUDED.bedrooms ELSE listings.bedrooms END,
+		bathrooms = CASE WHEN EXCLUDED.bathrooms_confidence > 0 THEN EXCLUDED.bathrooms ELSE listings.bathrooms END,
+		guests = CASE WHEN EXCLUDED.guests_confidence > 0 THEN EXCLUDED.guests ELSE listings.guests END,
+		beds = CASE WHEN EXCLUDED.beds_confidence > 0 THEN EXCLUDED.beds ELSE listings.beds END,
+		half_baths = CASE WHEN EXCLUDED.bathrooms_confidence > 0 THEN EXCLUDED.half_baths ELSE listings.half_baths END,
+		bathroom_type = CASE WHEN EXCLUDED.bathrooms_confidence > 0 THEN EXCLUDED.bathroom_type ELSE listings.bathroom_type END,
+		studio = CASE WHEN EXCLUDED.bedrooms_confidence > 0 THEN EXCLUDED.studio ELSE listings.studio END,
+		guests_confidence = CASE WHEN EXCLUDED.guests_confidence > 0 THEN EXCLUDED.guests_confidence ELSE listings.guests_confidence END,
+		bedrooms_confidence = CASE WHEN EXCLUDED.bedrooms_confidence > 0 THEN EXCLUDED.bedrooms_confidence ELSE listings.bedrooms_confidence END,
+		beds_confidence = CASE WHEN EXCLUDED.beds_confidence > 0 THEN EXCLUDED.beds_confidence ELSE listings.beds_confidence END,
+		bathrooms_confidence = CASE WHEN EXCLUDED.bathrooms_confidence > 0 THEN EXCLUDED.bathrooms_confidence ELSE listings.bathrooms_confidence END,
+		latitude = CASE WHEN EXCLUDED.latitude <> 0 THEN EXCLUDED.latitude ELSE listings.latitude END,
+		longitude = CASE WHEN EXCLUDED.longitude <> 0 THEN EXCLUDED.longitude ELSE listings.longitude END,
+		amenities = CASE WHEN EXCLUDED.amenities <> 0 THEN EXCLUDED.amenities ELSE listings.amenities END,
+		updated_at = CURRENT_TIMESTAMP
+	RETURNING id, bedrooms, beds, bathrooms, half_baths, bathroom_type, studio, guests,
+		guests_confidence, bedrooms_confidence, beds_confidence, bathrooms_confidence
+`
 
+// InsertListing inserts a new listing or updates if the listing ID already exists
+// Known capacity, coordinates and amenity counts are kept when a later scrape could not read them;
+// the listing's capacity fields are updated to the stored values so snapshots record them
+func (db *DB) InsertListing(listing *models.Listing) error {
 	err := db.conn.QueryRow(
-		query,
+		upsertListingSQL,
+		listing.ListingID,
 		listing.Title,
 		listing.Price,
 		listing.Location,
+		listing.PropertyType,
+		listing.City,
+		listing.SearchLocation,
 		listing.Rating,
+		listing.ReviewCount,
 		listing.URL,
 		listing.Bedrooms,
 		listing.Bathrooms,
 		listing.Guests,
-	).Scan(&listing.ID)
+		listing.Latitude,
+		listing.Longitude,
+		listing.Amenities,
+		listing.Beds,
+		listing.HalfBaths,
+		listing.BathroomType,
+		listing.Studio,
+		listing.Confidence.Guests,
+		listing.Confidence.Bedrooms,
+		listing.Confidence.Beds,
+		listing.Confidence.Bathrooms,
+	).Scan(
+		&listing.ID, &listing.Bedrooms, &listing.Beds, &listing.Bathrooms, &listing.HalfBaths,
+		&listing.BathroomType, &listing.Studio, &listing.Guests,
+		&listing.Confidence.Guests, &listing.Confidence.Bedrooms, &listing.Confidence.Beds, &listing.Confidence.Bathrooms,
+	)
 
 	if err != nil {
 		return fmt.Errorf("failed to insert listing: %w", err)
@@ -88,12 +197,31 @@ func (db *DB) InsertListing(listing *models.Listing) error {
 	return nil
 }
 
+// listingColumns is the column list shared by listing queries; it joins the canonical location,
+// the duplicate cluster and the market segment (listingJoins)
+const listingColumns = `
+	l.id, l.listing_id, l.title, l.price, l.location, l.property_type, l.city, l.search_location,
+	COALESCE(l.location_id, 0), COALESCE(loc.city, ''), COALESCE(loc.region, ''), COALESCE(loc.country, ''),
+	l.rating, l.review_count, l.url, l.bedrooms, l.bathrooms, l.guests,
+	l.latitude, l.longitude, l.amenities, l.beds, l.half_baths, l.bathroom_type, l.studio,
+	l.guests_confidence, l.bedrooms_confidence, l.beds_confidence, l.bathrooms_confidence,
+	COALESCE(d.cluster_id, 0), COALESCE(seg.label, ''),
+	l.created_at, l.updated_at
+`
+
+// listingJoins are the joins listingColumns reads from
+const listingJoins = `
+	LEFT JOIN locations loc ON loc.id = l.location_id
+	LEFT JOIN listing_duplicates d ON d.listing_id = l.id
+	LEFT JOIN listing_segments seg ON seg.listing_id = l.id
+`
+
 // GetAllListings retrieves all listings from the database
 func (db *DB) GetAllListings() ([]models.Listing, error) {
 	query := `
-		SELECT id, title, price, location, rating, url, bedrooms, bathrooms, guests, created_at, updated_at
-		FROM listings
-		ORDER BY created_at DESC
+		SELECT ` + listingColumns + `
+		FROM listings l ` + listingJoins + `
+		ORDER BY l.created_at DESC
 	`
 
 	rows, err := db.conn.Query(query)
@@ -102,23 +230,101 @@ func (db *DB) GetAllListings() ([]models.Listing, error) {
 	}
 	defer rows.Close()
 
+	return scanListings(rows)
+}
+
+// GetUnresolvedListings retrieves listings that are not linked to a canonical location
+func (db *DB) GetUnresolvedListings() ([]models.Listing, error) {
+	query := `
+		SELECT ` + listingColumns + `
+		FROM listings l ` + listingJoins + `
+		WHERE l.location_id IS NULL
+		ORDER BY l.id
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unresolved listings: %w", err)
+	}
+	defer rows.Close()
+
+	return scanListings(rows)
+}
+
+// scanListings reads rows selected with listingColumns
+func scanListings(rows *sql.Rows) ([]models.Listing, error) {
 	var listings []models.Listing
 	for rows.Next() {
 		var l models.Listing
+		var locCity, locRegion, locCountry string
 		err := rows.Scan(
-			&l.ID, &l.Title, &l.Price, &l.Location, &l.Rating,
-			&l.URL, &l.Bedrooms, &l.Bathrooms, &l.Guests,
+			&l.ID, &l.ListingID, &l.Title, &l.Price, &l.Location, &l.PropertyType, &l.City, &l.SearchLocation,
+			&l.LocationID, &locCity, &locRegion, &locCountry,
+			&l.Rating, &l.ReviewCount, &l.URL, &l.Bedrooms, &l.Bathrooms, &l.Guests,
+			&l.Latitude, &l.Longitude, &l.Amenities, &l.Beds, &l.HalfBaths, &l.BathroomType, &l.Studio,
+			&l.Confidence.Guests, &l.Confidence.Bedrooms, &l.Confidence.Beds, &l.Confidence.Bathrooms,
+			&l.ClusterID, &l.Segment,
 			&l.CreatedAt, &l.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan listing: %w", err)
 		}
+
+		if l.LocationID != 0 {
+			loc := models.Location{City: locCity, Region: locRegion, Country: locCountry}
+			l.CanonicalLocation = loc.DisplayName()
+		}
+
 		listings = append(listings, l)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read listings: %w", err)
+	}
+
 	return listings, nil
 }
 
+// UpsertLocation inserts a canonical location or returns the existing row for its GeoNames ID
+func (db *DB) UpsertLocation(location *models.Location) error {
+	query := `
+		INSERT INTO locations (geoname_id, city, region, country, country_code, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (geoname_id) DO UPDATE SET
+			city = EXCLUDED.city,
+			region = EXCLUDED.region,
+			country = EXCLUDED.country,
+			country_code = EXCLUDED.country_code
+		RETURNING id, created_at
+	`
+
+	err := db.conn.QueryRow(
+		query,
+		location.GeonameID,
+		location.City,
+		location.Region,
+		location.Country,
+		location.CountryCode,
+		location.Latitude,
+		location.Longitude,
+	).Scan(&location.ID, &location.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert location: %w", err)
+	}
+
+	return nil
+}
+
+// SetListingLocation links a listing to a canonical location
+func (db *DB) SetListingLocation(listingID, locationID int) error {
+	_, err := db.conn.Exec(`UPDATE listings SET location_id = $1 WHERE id = $2`, locationID, listingID)
+	if err != nil {
+		return fmt.Errorf("failed to set listing location: %w", err)
+	}
+	return nil
+}
+
 // close the database connection
 func (db *DB) Close() error {
 	return db.conn.Close()
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// capacityGuards maps each capacity column to the confidence that must be above 0 to overwrite it
+var capacityGuards = map[string]string{
+	"bedrooms":      "bedrooms_confidence",
+	"studio":        "bedrooms_confidence",
+	"beds":          "beds_confidence",
+	"bathrooms":     "bathrooms_confidence",
+	"half_baths":    "bathrooms_confidence",
+	"bathroom_type": "bathrooms_confidence",
+	"guests":        "guests_confidence",
+}
+
+func TestUpsertListingGuardsCapacity(t *testing.T) {
+	query := strings.Join(strings.Fields(upsertListingSQL), " ")
+	returning := query[strings.Index(query, "RETURNING"):]
+
+	for column, confidence := range capacityGuards {
+		guard := column + " = CASE WHEN EXCLUDED." + confidence + " > 0 THEN EXCLUDED." + column +
+			" ELSE listings." + column + " END"
+		if !strings.Contains(query, guard) {
+			t.Errorf("upsert does not guard %s with %s", column, confidence)
+		}
+		if !strings.Contains(returning, " "+column+",") {
+			t.Errorf("upsert does not return the stored %s", column)
+		}
+	}
+}
+
+// TestInsertListingKeepsCapacityOnFailedDetail needs a PostgreSQL database in TEST_DATABASE_URL
+func TestInsertListingKeepsCapacityOnFailedDetail(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := NewDB(dsn)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	const roomID = "9990000000000001"
+	cleanup := func() { db.conn.Exec(`DELETE FROM listings WHERE listing_id = $1`, roomID) }
+	cleanup()
+	defer cleanup()
+
+	detailed := models.Listing{
+		ListingID: roomID, Title: "Loft in Richmond", Price: 120, URL: "https://www.airbnb.com/rooms/" + roomID,
+		Bedrooms: 2, Beds: 3, Bathrooms: 1, HalfBaths: 1, BathroomType: "private", Guests: 4,
+		Confidence: models.DetailConfidence{Guests: 1, Bedrooms: 1, Beds: 0.8, Bathrooms: 1},
+	}
+	if err := db.InsertListing(&detailed); err != nil {
+		t.Fatalf("InsertListing: %v", err)
+	}
+
+	// A re-crawl whose detail page failed carries zero capacity and zero confidence
+	failed := models.Listing{
+		ListingID: roomID, Title: "Loft in Richmond", Price: 125, URL: detailed.URL,
+	}
+	if err := db.InsertListing(&failed); err != nil {
+		t.Fatalf("InsertListing: %v", err)
+	}
+
+	if failed.Bedrooms != 2 || failed.Beds != 3 || failed.Bathrooms != 1 || failed.HalfBaths != 1 ||
+		failed.BathroomType != "private" || failed.Guests != 4 {
+		t.Errorf("stored capacity was overwritten: %+v", failed)
+	}
+	if failed.Confidence != detailed.Confidence {
+		t.Errorf("confidence = %+v, want %+v", failed.Confidence, detailed.Confidence)
+	}
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// UpsertDiscoveredLocations records the searches found on the homepage
+// Known searches get their URL and last-seen date refreshed; new ones also get a first-seen date.
+// Returns the number of searches seen for the first time.
+func (db *DB) UpsertDiscoveredLocations(found []models.DiscoveredLocation) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// xmax is 0 only for rows this statement inserted
+	query := `
+		INSERT INTO discovered_locations (kind, group_name, region, name, url)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (kind, group_name, region, name) DO UPDATE SET
+			url = EXCLUDED.url,
+			last_seen_at = CURRENT_TIMESTAMP
+		RETURNING xmax = 0
+	`
+
+	added := 0
+	for _, d := range found {
+		var inserted bool
+		if err := tx.QueryRow(query, d.Kind, d.Group, d.Region, d.Name, d.URL).Scan(&inserted); err != nil {
+			return 0, fmt.Errorf("failed to save discovered location %s: %w", d.Name, err)
+		}
+		if inserted {
+			added++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit discovered locations: %w", err)
+	}
+	return added, nil
+}
+
+// GetDiscoveredLocations retrieves every search ever discovered on the homepage
+func (db *DB) GetDiscoveredLocations() ([]models.DiscoveredLocation, error) {
+	query := `
+		SELECT id, kind, group_name, region, name, url, first_seen_at, last_seen_at
+		FROM discovered_locations
+		ORDER BY kind, group_name, region, name
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query discovered locations: %w", err)
+	}
+	defer rows.Close()
+
+	var discovered []models.DiscoveredLocation
+	for rows.Next() {
+		var d models.DiscoveredLocation
+		if err := rows.Scan(&d.ID, &d.Kind, &d.Group, &d.Region, &d.Name, &d.URL, &d.FirstSeenAt, &d.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan discovered location: %w", err)
+		}
+		discovered = append(discovered, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read discovered locations: %w", err)
+	}
+
+	return discovered, nil
+}
@@ -0,0 +1,33 @@
+package storage
+
+import "fmt"
+
+// ReplaceDuplicateClusters replaces the stored duplicate clusters
+// clusters maps each duplicate listing id to its cluster id; scores holds the match score stored with each listing
+func (db *DB) ReplaceDuplicateClusters(clusters map[int]int, scores map[int]float64) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM listing_duplicates`); err != nil {
+		return fmt.Errorf("failed to clear duplicate clusters: %w", err)
+	}
+
+	for listingID, clusterID := range clusters {
+		_, err := tx.Exec(
+			`INSERT INTO listing_duplicates (listing_id, cluster_id, score) VALUES ($1, $2, $3)`,
+			listingID, clusterID, scores[listingID],
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save duplicate cluster: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit duplicate clusters: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// InsertModelRun stores the evaluation metrics of a model training run
+func (db *DB) InsertModelRun(run *models.ModelRun) error {
+	query := `
+		INSERT INTO model_runs (algorithm, samples, test_samples, features, lambda, mae, r2)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, trained_at
+	`
+
+	err := db.conn.QueryRow(
+		query,
+		run.Algorithm,
+		run.Samples,
+		run.TestSamples,
+		run.Features,
+		run.Lambda,
+		run.MAE,
+		run.R2,
+	).Scan(&run.ID, &run.TrainedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert model run: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecentModelRuns retrieves the last limit model training runs, newest first
+func (db *DB) GetRecentModelRuns(limit int) ([]models.ModelRun, error) {
+	query := `
+		SELECT id, algorithm, samples, test_samples, features, lambda, mae, r2, trained_at
+		FROM model_runs
+		ORDER BY trained_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := db.conn.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query model runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []models.ModelRun
+	for rows.Next() {
+		var run models.ModelRun
+		err := rows.Scan(&run.ID, &run.Algorithm, &run.Samples, &run.TestSamples, &run.Features,
+			&run.Lambda, &run.MAE, &run.R2, &run.TrainedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan model run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read model runs: %w", err)
+	}
+
+	return runs, nil
+}
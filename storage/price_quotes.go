@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// InsertPriceQuotes stores the quotes of a date sweep
+func (db *DB) InsertPriceQuotes(quotes []models.PriceQuote) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO price_quotes (listing_id, check_in, nights, adults, available, total_price, nightly_price, price_basis)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	for _, q := range quotes {
+		if _, err := tx.Exec(query, q.ListingID, q.CheckIn, q.Nights, q.Adults, q.Available, q.TotalPrice, q.NightlyPrice, q.PriceBasis); err != nil {
+			return fmt.Errorf("failed to save price quote: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit price quotes: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestPriceQuotes returns the most recent quote per listing, check-in date and stay length
+// for check-in dates from today on
+func (db *DB) GetLatestPriceQuotes() ([]models.PriceQuote, error) {
+	query := `
+		SELECT DISTINCT ON (listing_id, check_in, nights)
+		       id, listing_id, check_in, nights, adults, available, total_price, nightly_price, price_basis, quoted_at
+		FROM price_quotes
+		WHERE check_in >= CURRENT_DATE
+		ORDER BY listing_id, check_in, nights, quoted_at DESC
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price quotes: %w", err)
+	}
+	defer rows.Close()
+
+	var quotes []models.PriceQuote
+	for rows.Next() {
+		var q models.PriceQuote
+		if err := rows.Scan(&q.ID, &q.ListingID, &q.CheckIn, &q.Nights, &q.Adults, &q.Available,
+			&q.TotalPrice, &q.NightlyPrice, &q.PriceBasis, &q.QuotedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price quote: %w", err)
+		}
+		quotes = append(quotes, q)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read price quotes: %w", err)
+	}
+
+	return quotes, nil
+}
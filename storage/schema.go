@@ -5,11 +5,15 @@ const (
 	CreateListingsTableSQL = `
 	CREATE TABLE IF NOT EXISTS listings (
 		id SERIAL PRIMARY KEY,
+		listing_id TEXT NOT NULL,
 		title TEXT NOT NULL,
 		price DECIMAL(10, 2) NOT NULL,
 		location TEXT NOT NULL,
+		property_type TEXT NOT NULL DEFAULT '',
+		city TEXT NOT NULL DEFAULT '',
 		rating DECIMAL(3, 2) DEFAULT 0.0,
-		url TEXT UNIQUE NOT NULL, 
+		review_count INTEGER NOT NULL DEFAULT 0,
+		url TEXT NOT NULL,
 		bedrooms INTEGER DEFAULT 0,
 		bathrooms INTEGER DEFAULT 0,
 		guests INTEGER DEFAULT 0,
@@ -26,8 +30,285 @@ const (
 	-- Index on rating for top-rated queries
 	CREATE INDEX IF NOT EXISTS idx_listings_rating ON listings(rating DESC);
 	
-	-- Unique constraint on URL prevents duplicates
-	CREATE UNIQUE INDEX IF NOT EXISTS idx_listings_url ON listings(url);
+	-- Index on URL for lookups by link
+	CREATE INDEX IF NOT EXISTS idx_listings_url ON listings(url);
+	`
+
+	// MigrateListingIDSQL makes the Airbnb room ID the natural key of listings.
+	// Older databases were keyed by url, so the same room could be stored once per
+	// URL variant; those rows are merged into the most recently updated one.
+	MigrateListingIDSQL = `
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS listing_id TEXT;
+
+	-- Backfill from the URL, falling back to the URL itself when it has no room ID
+	UPDATE listings
+	SET listing_id = COALESCE(substring(url from '/(?:rooms(?:/plus)?|luxury/listing)/([0-9]+)'), url)
+	WHERE listing_id IS NULL;
+
+	-- Merge duplicates: keep the newest row, carrying over the earliest created_at
+	WITH ranked AS (
+		SELECT id,
+			ROW_NUMBER() OVER (PARTITION BY listing_id ORDER BY updated_at DESC, id DESC) AS rn,
+			MIN(created_at) OVER (PARTITION BY listing_id) AS first_seen
+		FROM listings
+	), merged AS (
+		UPDATE listings l SET created_at = r.first_seen
+		FROM ranked r
+		WHERE l.id = r.id AND r.rn = 1 AND l.created_at <> r.first_seen
+		RETURNING l.id
+	)
+	DELETE FROM listings l USING ranked r
+	WHERE l.id = r.id AND r.rn > 1;
+
+	ALTER TABLE listings ALTER COLUMN listing_id SET NOT NULL;
+
+	-- URL is no longer the natural key
+	ALTER TABLE listings DROP CONSTRAINT IF EXISTS listings_url_key;
+	DROP INDEX IF EXISTS idx_listings_url;
+	CREATE INDEX IF NOT EXISTS idx_listings_url ON listings(url);
+
+	-- Unique constraint on listing_id prevents duplicates
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_listings_listing_id ON listings(listing_id);
+	`
+
+	// MigratePropertyTypeSQL adds property type and city columns parsed from the
+	// "<type> in <place>" card headline. Existing rows are backfilled with a plain split;
+	// new rows are normalized by utils.ParsePropertyHeadline.
+	MigratePropertyTypeSQL = `
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS property_type TEXT NOT NULL DEFAULT '';
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS city TEXT NOT NULL DEFAULT '';
+
+	UPDATE listings
+	SET property_type = COALESCE(substring(location from '^(.*?) in '), ''),
+		city = COALESCE(substring(location from '^.* in (.*)$'), location)
+	WHERE property_type = '' AND city = '';
+
+	CREATE INDEX IF NOT EXISTS idx_listings_property_type ON listings(property_type);
+	CREATE INDEX IF NOT EXISTS idx_listings_city ON listings(city);
+	`
+
+	// CreateLocationsTableSQL creates the canonical locations table and links listings to it
+	CreateLocationsTableSQL = `
+	CREATE TABLE IF NOT EXISTS locations (
+		id SERIAL PRIMARY KEY,
+		geoname_id BIGINT UNIQUE NOT NULL,
+		city TEXT NOT NULL,
+		region TEXT NOT NULL DEFAULT '',
+		country TEXT NOT NULL DEFAULT '',
+		country_code TEXT NOT NULL DEFAULT '',
+		latitude DOUBLE PRECISION DEFAULT 0,
+		longitude DOUBLE PRECISION DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS search_location TEXT NOT NULL DEFAULT '';
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES locations(id) ON DELETE SET NULL;
+
+	-- Index on location_id for grouping by canonical location
+	CREATE INDEX IF NOT EXISTS idx_listings_location_id ON listings(location_id);
+	`
+
+	// MigrateReviewCountSQL adds the review count used to weight ratings
+	MigrateReviewCountSQL = `
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS review_count INTEGER NOT NULL DEFAULT 0;
+	`
+
+	// CreateSnapshotsTableSQL creates the scrape run log and the per-run listing snapshots
+	// used for price history. Listings that predate snapshots get one seeded from their
+	// current values so trends have a starting point.
+	CreateSnapshotsTableSQL = `
+	CREATE TABLE IF NOT EXISTS scrape_runs (
+		id SERIAL PRIMARY KEY,
+		kind TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'running',
+		listings_found INTEGER NOT NULL DEFAULT 0,
+		listings_saved INTEGER NOT NULL DEFAULT 0,
+		started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		finished_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS listing_snapshots (
+		id SERIAL PRIMARY KEY,
+		run_id INTEGER REFERENCES scrape_runs(id) ON DELETE CASCADE,
+		listing_id INTEGER NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+		price DECIMAL(10, 2) NOT NULL,
+		rating DECIMAL(3, 2) DEFAULT 0.0,
+		review_count INTEGER NOT NULL DEFAULT 0,
+		bedrooms INTEGER DEFAULT 0,
+		bathrooms INTEGER DEFAULT 0,
+		guests INTEGER DEFAULT 0,
+		captured_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Index for time series per listing
+	CREATE INDEX IF NOT EXISTS idx_snapshots_listing_time ON listing_snapshots(listing_id, captured_at);
+
+	-- Index for comparing runs
+	CREATE INDEX IF NOT EXISTS idx_snapshots_run ON listing_snapshots(run_id);
+
+	INSERT INTO listing_snapshots (listing_id, price, rating, review_count, bedrooms, bathrooms, guests, captured_at)
+	SELECT l.id, l.price, l.rating, l.review_count, l.bedrooms, l.bathrooms, l.guests, l.updated_at
+	FROM listings l
+	WHERE NOT EXISTS (SELECT 1 FROM listing_snapshots s WHERE s.listing_id = l.id);
+	`
+
+	// CreateAlertNotificationsTableSQL records the alerts already delivered so each is sent once
+	CreateAlertNotificationsTableSQL = `
+	CREATE TABLE IF NOT EXISTS alert_notifications (
+		id SERIAL PRIMARY KEY,
+		rule_name TEXT NOT NULL,
+		alert_key TEXT NOT NULL,
+		run_id INTEGER REFERENCES scrape_runs(id) ON DELETE SET NULL,
+		subject TEXT NOT NULL,
+		message TEXT NOT NULL,
+		sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (rule_name, alert_key)
+	);
+	`
+
+	// MigrateRunHealthSQL adds the per-run health metrics and the per-location listing counts
+	// used as the baseline for later runs
+	MigrateRunHealthSQL = `
+	ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS pages_scraped INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS cards_found INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS details_attempted INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS details_succeeded INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS empty_field_share DECIMAL(5, 4) NOT NULL DEFAULT 0;
+	ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS health_issues TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE IF NOT EXISTS scrape_run_locations (
+		run_id INTEGER NOT NULL REFERENCES scrape_runs(id) ON DELETE CASCADE,
+		location TEXT NOT NULL,
+		listings INTEGER NOT NULL,
+		PRIMARY KEY (run_id, location)
+	);
+	`
+
+	// MigrateDuplicatesSQL adds listing coordinates and the probable duplicate clusters.
+	// A cluster is identified by the id of its lowest listing.
+	MigrateDuplicatesSQL = `
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION NOT NULL DEFAULT 0;
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+	CREATE TABLE IF NOT EXISTS listing_duplicates (
+		listing_id INTEGER PRIMARY KEY REFERENCES listings(id) ON DELETE CASCADE,
+		cluster_id INTEGER NOT NULL,
+		score DECIMAL(4, 3) NOT NULL DEFAULT 0,
+		detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_listing_duplicates_cluster ON listing_duplicates(cluster_id);
+	`
+
+	// CreateModelRunsTableSQL records the evaluation metrics of each fair-price model training run
+	CreateModelRunsTableSQL = `
+	CREATE TABLE IF NOT EXISTS model_runs (
+		id SERIAL PRIMARY KEY,
+		algorithm TEXT NOT NULL,
+		samples INTEGER NOT NULL,
+		test_samples INTEGER NOT NULL,
+		features INTEGER NOT NULL,
+		lambda DOUBLE PRECISION NOT NULL,
+		mae DOUBLE PRECISION NOT NULL,
+		r2 DOUBLE PRECISION NOT NULL,
+		trained_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	// CreateListingCalendarTableSQL creates the per-night availability calendar of each listing
+	CreateListingCalendarTableSQL = `
+	CREATE TABLE IF NOT EXISTS listing_calendar (
+		listing_id INTEGER NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+		date DATE NOT NULL,
+		available BOOLEAN NOT NULL,
+		price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+		min_nights INTEGER NOT NULL DEFAULT 0,
+		scraped_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (listing_id, date)
+	);
+
+	-- Index for windows of upcoming nights
+	CREATE INDEX IF NOT EXISTS idx_listing_calendar_date ON listing_calendar(date);
+	`
+
+	// CreatePriceQuotesTableSQL creates the price quotes of date sweeps
+	// Every sweep adds new rows; reports use the latest quote per listing, check-in and stay length
+	CreatePriceQuotesTableSQL = `
+	CREATE TABLE IF NOT EXISTS price_quotes (
+		id SERIAL PRIMARY KEY,
+		listing_id INTEGER NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+		check_in DATE NOT NULL,
+		nights INTEGER NOT NULL,
+		adults INTEGER NOT NULL DEFAULT 0,
+		available BOOLEAN NOT NULL,
+		total_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+		nightly_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+		quoted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Index for the latest quote per listing, check-in and stay length
+	CREATE INDEX IF NOT EXISTS idx_price_quotes_key ON price_quotes(listing_id, check_in, nights, quoted_at DESC);
+	`
+
+	// MigrateSegmentsSQL adds the amenity count of listings and their market segment assignment
+	MigrateSegmentsSQL = `
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS amenities INTEGER NOT NULL DEFAULT 0;
+
+	CREATE TABLE IF NOT EXISTS listing_segments (
+		listing_id INTEGER PRIMARY KEY REFERENCES listings(id) ON DELETE CASCADE,
+		segment_id INTEGER NOT NULL,
+		label TEXT NOT NULL,
+		assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_listing_segments_label ON listing_segments(label);
+	`
+
+	// MigrateDetailFieldsSQL adds the bed, bath and studio details read from listing overviews
+	// and the confidence of each capacity field
+	MigrateDetailFieldsSQL = `
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS beds INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS half_baths INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS bathroom_type TEXT NOT NULL DEFAULT '';
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS studio BOOLEAN NOT NULL DEFAULT FALSE;
+
+	-- How each capacity field was read, 0 when the detail page was never read
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS guests_confidence DECIMAL(3, 2) NOT NULL DEFAULT 0;
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS bedrooms_confidence DECIMAL(3, 2) NOT NULL DEFAULT 0;
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS beds_confidence DECIMAL(3, 2) NOT NULL DEFAULT 0;
+	ALTER TABLE listings ADD COLUMN IF NOT EXISTS bathrooms_confidence DECIMAL(3, 2) NOT NULL DEFAULT 0;
+	`
+
+	// MigrateQuoteBasisSQL records whether a quote total includes fees or is the nightly rate times the nights
+	MigrateQuoteBasisSQL = `
+	ALTER TABLE price_quotes ADD COLUMN IF NOT EXISTS price_basis TEXT NOT NULL DEFAULT 'total';
+	`
+
+	// CreateWatchlistTableSQL creates the watchlist of listings refreshed by --refresh-watchlist
+	CreateWatchlistTableSQL = `
+	CREATE TABLE IF NOT EXISTS watchlist (
+		id SERIAL PRIMARY KEY,
+		listing_id TEXT NOT NULL UNIQUE,
+		url TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_refreshed_at TIMESTAMP
+	);
+	`
+
+	// CreateDiscoveredLocationsTableSQL creates the table of searches discovered on the homepage
+	CreateDiscoveredLocationsTableSQL = `
+	CREATE TABLE IF NOT EXISTS discovered_locations (
+		id SERIAL PRIMARY KEY,
+		kind TEXT NOT NULL,
+		group_name TEXT NOT NULL DEFAULT '',
+		region TEXT NOT NULL DEFAULT '',
+		name TEXT NOT NULL,
+		url TEXT NOT NULL,
+		first_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (kind, group_name, region, name)
+	);
 	`
 
 	// UpdateUpdatedAtTriggerSQL creates a trigger to auto-update updated_at
@@ -0,0 +1,33 @@
+package storage
+
+import "fmt"
+
+// ReplaceSegments replaces the stored market segment assignments
+// segments maps each listing id to its segment id; labels maps each segment id to its label
+func (db *DB) ReplaceSegments(segments map[int]int, labels map[int]string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM listing_segments`); err != nil {
+		return fmt.Errorf("failed to clear segments: %w", err)
+	}
+
+	for listingID, segmentID := range segments {
+		_, err := tx.Exec(
+			`INSERT INTO listing_segments (listing_id, segment_id, label) VALUES ($1, $2, $3)`,
+			listingID, segmentID, labels[segmentID],
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save segment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit segments: %w", err)
+	}
+
+	return nil
+}
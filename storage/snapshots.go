@@ -0,0 +1,318 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// CreateScrapeRun records the start of a scrape run
+func (db *DB) CreateScrapeRun(kind string) (*models.ScrapeRun, error) {
+	run := &models.ScrapeRun{Kind: kind, Status: "running"}
+
+	err := db.conn.QueryRow(
+		`INSERT INTO scrape_runs (kind, status) VALUES ($1, $2) RETURNING id, started_at`,
+		run.Kind, run.Status,
+	).Scan(&run.ID, &run.StartedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scrape run: %w", err)
+	}
+
+	return run, nil
+}
+
+// FinishScrapeRun stores the final status, counts and health metrics of a scrape run
+func (db *DB) FinishScrapeRun(run *models.ScrapeRun) error {
+	query := `
+		UPDATE scrape_runs
+		SET status = $1, listings_found = $2, listings_saved = $3,
+		    pages_scraped = $4, cards_found = $5, details_attempted = $6, details_succeeded = $7,
+		    empty_field_share = $8, health_issues = $9, finished_at = CURRENT_TIMESTAMP
+		WHERE id = $10
+		RETURNING finished_at
+	`
+
+	var finishedAt time.Time
+	err := db.conn.QueryRow(
+		query,
+		run.Status, run.ListingsFound, run.ListingsSaved,
+		run.PagesScraped, run.CardsFound, run.DetailsAttempted, run.DetailsSucceeded,
+		run.EmptyFieldShare, run.HealthIssues, run.ID,
+	).Scan(&finishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish scrape run: %w", err)
+	}
+
+	run.FinishedAt = &finishedAt
+	return nil
+}
+
+// InsertSnapshot records the current values of a saved listing for a scrape run
+func (db *DB) InsertSnapshot(runID int, listing *models.Listing) error {
+	query := `
+		INSERT INTO listing_snapshots (run_id, listing_id, price, rating, review_count, bedrooms, bathrooms, guests)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := db.conn.Exec(
+		query,
+		nullableID(runID),
+		listing.ID,
+		listing.Price,
+		listing.Rating,
+		listing.ReviewCount,
+		listing.Bedrooms,
+		listing.Bathrooms,
+		listing.Guests,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotColumns is the column list shared by snapshot queries; it joins the listing and its location
+const snapshotColumns = `
+	s.id, COALESCE(s.run_id, 0), s.listing_id, s.price, s.rating, s.review_count,
+	s.bedrooms, s.bathrooms, s.guests, s.captured_at,
+	l.title, l.url, l.location, l.city, COALESCE(loc.city, ''), COALESCE(loc.region, ''), COALESCE(loc.country, ''),
+	l.created_at
+`
+
+// GetSnapshotsSince retrieves all snapshots captured at or after since, oldest first
+func (db *DB) GetSnapshotsSince(since time.Time) ([]models.ListingSnapshot, error) {
+	query := `
+		SELECT ` + snapshotColumns + `
+		FROM listing_snapshots s
+		JOIN listings l ON l.id = s.listing_id
+		LEFT JOIN locations loc ON loc.id = l.location_id
+		WHERE s.captured_at >= $1
+		ORDER BY s.captured_at, s.id
+	`
+
+	rows, err := db.conn.Query(query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+// scanSnapshots reads rows selected with snapshotColumns
+func scanSnapshots(rows *sql.Rows) ([]models.ListingSnapshot, error) {
+	var snapshots []models.ListingSnapshot
+	for rows.Next() {
+		var s models.ListingSnapshot
+		var locCity, locRegion, locCountry string
+		err := rows.Scan(
+			&s.ID, &s.RunID, &s.ListingID, &s.Price, &s.Rating, &s.ReviewCount,
+			&s.Bedrooms, &s.Bathrooms, &s.Guests, &s.CapturedAt,
+			&s.Title, &s.URL, &s.Location, &s.City, &locCity, &locRegion, &locCountry,
+			&s.ListingCreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+
+		if locCity != "" {
+			loc := models.Location{City: locCity, Region: locRegion, Country: locCountry}
+			s.CanonicalLocation = loc.DisplayName()
+		}
+
+		snapshots = append(snapshots, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// nullableID maps 0 to NULL for optional foreign keys
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// GetScrapeRun retrieves one scrape run by id
+func (db *DB) GetScrapeRun(id int) (*models.ScrapeRun, error) {
+	runs, err := db.queryScrapeRuns(`WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("scrape run %d not found", id)
+	}
+	return &runs[0], nil
+}
+
+// GetRecentScrapeRuns retrieves the last limit scrape runs, newest first
+func (db *DB) GetRecentScrapeRuns(limit int) ([]models.ScrapeRun, error) {
+	return db.queryScrapeRuns(`ORDER BY started_at DESC, id DESC LIMIT $1`, limit)
+}
+
+// queryScrapeRuns selects scrape runs with the given WHERE/ORDER clause
+func (db *DB) queryScrapeRuns(clause string, args ...interface{}) ([]models.ScrapeRun, error) {
+	query := `
+		SELECT id, kind, status, listings_found, listings_saved, started_at, finished_at,
+		       pages_scraped, cards_found, details_attempted, details_succeeded, empty_field_share, health_issues
+		FROM scrape_runs
+	` + clause
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []models.ScrapeRun
+	for rows.Next() {
+		var run models.ScrapeRun
+		var finishedAt sql.NullTime
+		err := rows.Scan(
+			&run.ID, &run.Kind, &run.Status, &run.ListingsFound, &run.ListingsSaved, &run.StartedAt, &finishedAt,
+			&run.PagesScraped, &run.CardsFound, &run.DetailsAttempted, &run.DetailsSucceeded,
+			&run.EmptyFieldShare, &run.HealthIssues,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scrape run: %w", err)
+		}
+		if finishedAt.Valid {
+			run.FinishedAt = &finishedAt.Time
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read scrape runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetSnapshotsForRun retrieves the snapshots captured by one scrape run
+func (db *DB) GetSnapshotsForRun(runID int) ([]models.ListingSnapshot, error) {
+	query := `
+		SELECT ` + snapshotColumns + `
+		FROM listing_snapshots s
+		JOIN listings l ON l.id = s.listing_id
+		LEFT JOIN locations loc ON loc.id = l.location_id
+		WHERE s.run_id = $1
+		ORDER BY s.captured_at, s.id
+	`
+
+	rows, err := db.conn.Query(query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+// GetSnapshotsBetween retrieves the snapshots captured in [from, to), oldest first
+func (db *DB) GetSnapshotsBetween(from, to time.Time) ([]models.ListingSnapshot, error) {
+	query := `
+		SELECT ` + snapshotColumns + `
+		FROM listing_snapshots s
+		JOIN listings l ON l.id = s.listing_id
+		LEFT JOIN locations loc ON loc.id = l.location_id
+		WHERE s.captured_at >= $1 AND s.captured_at < $2
+		ORDER BY s.captured_at, s.id
+	`
+
+	rows, err := db.conn.Query(query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+// GetPreviousSnapshots retrieves, for each listing captured by a run, its last snapshot
+// taken before that run
+func (db *DB) GetPreviousSnapshots(runID int) ([]models.ListingSnapshot, error) {
+	query := `
+		SELECT DISTINCT ON (s.listing_id) ` + snapshotColumns + `
+		FROM listing_snapshots s
+		JOIN listings l ON l.id = s.listing_id
+		LEFT JOIN locations loc ON loc.id = l.location_id
+		WHERE s.listing_id IN (SELECT listing_id FROM listing_snapshots WHERE run_id = $1)
+		  AND s.id < (SELECT MIN(id) FROM listing_snapshots WHERE run_id = $1)
+		ORDER BY s.listing_id, s.captured_at DESC, s.id DESC
+	`
+
+	rows, err := db.conn.Query(query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query previous snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+// SaveRunLocationCounts stores the number of listings each location returned in a run
+func (db *DB) SaveRunLocationCounts(runID int, counts map[string]int) error {
+	query := `
+		INSERT INTO scrape_run_locations (run_id, location, listings)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (run_id, location) DO UPDATE SET listings = EXCLUDED.listings
+	`
+
+	for location, count := range counts {
+		if _, err := db.conn.Exec(query, runID, location, count); err != nil {
+			return fmt.Errorf("failed to save location count: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetLocationBaselines returns the median listing count per location over the last
+// runs healthy completed runs before runID
+func (db *DB) GetLocationBaselines(runID, runs int) (map[string]float64, error) {
+	query := `
+		SELECT location, percentile_cont(0.5) WITHIN GROUP (ORDER BY listings)
+		FROM (
+			SELECT rl.location, rl.listings,
+			       ROW_NUMBER() OVER (PARTITION BY rl.location ORDER BY rl.run_id DESC) AS rn
+			FROM scrape_run_locations rl
+			JOIN scrape_runs r ON r.id = rl.run_id
+			WHERE rl.run_id < $1 AND r.status = 'completed' AND r.health_issues = ''
+		) recent
+		WHERE rn <= $2
+		GROUP BY location
+	`
+
+	rows, err := db.conn.Query(query, runID, runs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query location baselines: %w", err)
+	}
+	defer rows.Close()
+
+	baselines := make(map[string]float64)
+	for rows.Next() {
+		var location string
+		var median float64
+		if err := rows.Scan(&location, &median); err != nil {
+			return nil, fmt.Errorf("failed to scan location baseline: %w", err)
+		}
+		baselines[location] = median
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read location baselines: %w", err)
+	}
+
+	return baselines, nil
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/farhanasfar/airbnb-market-scraping-system/models"
+)
+
+// AddToWatchlist adds a listing to the watchlist
+// Returns false if the listing was already watched; its note is then updated when one is given
+func (db *DB) AddToWatchlist(listingID, url, note string) (bool, error) {
+	result, err := db.conn.Exec(
+		`INSERT INTO watchlist (listing_id, url, note) VALUES ($1, $2, $3) ON CONFLICT (listing_id) DO NOTHING`,
+		listingID, url, note,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to add to watchlist: %w", err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to add to watchlist: %w", err)
+	}
+
+	if added == 0 && note != "" {
+		if _, err := db.conn.Exec(`UPDATE watchlist SET note = $1 WHERE listing_id = $2`, note, listingID); err != nil {
+			return false, fmt.Errorf("failed to update watchlist note: %w", err)
+		}
+	}
+
+	return added > 0, nil
+}
+
+// RemoveFromWatchlist removes a listing from the watchlist
+// Returns false if the listing was not watched
+func (db *DB) RemoveFromWatchlist(listingID string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM watchlist WHERE listing_id = $1`, listingID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove from watchlist: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove from watchlist: %w", err)
+	}
+
+	return removed > 0, nil
+}
+
+// GetWatchlist returns the watched listings with their stored title and price, oldest first
+func (db *DB) GetWatchlist() ([]models.WatchedListing, error) {
+	query := `
+		SELECT w.id, w.listing_id, w.url, w.note, w.added_at, w.last_refreshed_at,
+		       COALESCE(l.title, ''), COALESCE(l.price, 0)
+		FROM watchlist w
+		LEFT JOIN listings l ON l.listing_id = w.listing_id
+		ORDER BY w.added_at, w.id
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	var watched []models.WatchedListing
+	for rows.Next() {
+		var w models.WatchedListing
+		if err := rows.Scan(&w.ID, &w.ListingID, &w.URL, &w.Note, &w.AddedAt, &w.LastRefreshedAt, &w.Title, &w.Price); err != nil {
+			return nil, fmt.Errorf("failed to scan watched listing: %w", err)
+		}
+		watched = append(watched, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read watchlist: %w", err)
+	}
+
+	return watched, nil
+}
+
+// MarkWatchlistRefreshed records that the given watched listings were refreshed now
+func (db *DB) MarkWatchlistRefreshed(listingIDs []string) error {
+	for _, id := range listingIDs {
+		if _, err := db.conn.Exec(`UPDATE watchlist SET last_refreshed_at = CURRENT_TIMESTAMP WHERE listing_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to mark watchlist refresh: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Place is a canonical city from the gazetteer
+type Place struct {
+	GeonameID   int64
+	City        string
+	Region      string
+	RegionCode  string
+	Country     string
+	CountryCode string
+	Latitude    float64
+	Longitude   float64
+	Population  int64
+}
+
+// Gazetteer resolves free-text place names against a GeoNames-style dump
+type Gazetteer struct {
+	byName    map[string][]*Place
+	regions   map[string]string // "AU.07" -> "Victoria"
+	countries map[string]string // "AU" -> "Australia"
+}
+
+// LoadGazetteer reads a GeoNames cities dump (e.g. cities15000.txt) together with
+// the admin1CodesASCII.txt and countryInfo.txt files used to name regions and countries.
+// The region and country files are optional.
+func LoadGazetteer(citiesFile, regionsFile, countriesFile string) (*Gazetteer, error) {
+	g := &Gazetteer{
+		byName:    make(map[string][]*Place),
+		regions:   make(map[string]string),
+		countries: make(map[string]string),
+	}
+
+	if countriesFile != "" {
+		err := readTSV(countriesFile, func(fields []string) {
+			if len(fields) > 4 {
+				g.countries[fields[0]] = fields[4]
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read countries file: %w", err)
+		}
+	}
+
+	if regionsFile != "" {
+		err := readTSV(regionsFile, func(fields []string) {
+			if len(fields) > 1 {
+				g.regions[fields[0]] = fields[1]
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read regions file: %w", err)
+		}
+	}
+
+	err := readTSV(citiesFile, func(fields []string) {
+		// geonameid, name, asciiname, alternatenames, lat, lng, feature class, feature code,
+		// country code, cc2, admin1 code, admin2, admin3, admin4, population, ...
+		if len(fields) < 15 {
+			return
+		}
+
+		id, _ := strconv.ParseInt(fields[0], 10, 64)
+		lat, _ := strconv.ParseFloat(fields[4], 64)
+		lng, _ := strconv.ParseFloat(fields[5], 64)
+		population, _ := strconv.ParseInt(fields[14], 10, 64)
+		countryCode := fields[8]
+
+		place := &Place{
+			GeonameID:   id,
+			City:        fields[1],
+			Region:      g.regions[countryCode+"."+fields[10]],
+			RegionCode:  fields[10],
+			Country:     g.countries[countryCode],
+			CountryCode: countryCode,
+			Latitude:    lat,
+			Longitude:   lng,
+			Population:  population,
+		}
+		if place.Country == "" {
+			place.Country = countryCode
+		}
+
+		names := append([]string{fields[1], fields[2]}, strings.Split(fields[3], ",")...)
+		added := make(map[string]bool)
+		for _, name := range names {
+			key := placeKey(name)
+			if key == "" || added[key] {
+				continue
+			}
+			added[key] = true
+			g.byName[key] = append(g.byName[key], place)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cities file: %w", err)
+	}
+
+	return g, nil
+}
+
+// Size returns the number of distinct names the gazetteer can resolve
+func (g *Gazetteer) Size() int {
+	return len(g.byName)
+}
+
+// Resolve maps free text like "Melbourne", "Melbourne, Australia" or "Melbourne VIC"
+// to a canonical place. hint is an optional second place name (e.g. the search location);
+// when it resolves, only candidates in the hint's country are accepted and those in its
+// region win ties. Returns nil when nothing matches.
+func (g *Gazetteer) Resolve(text, hint string) *Place {
+	text = CleanText(text)
+	if text == "" {
+		return nil
+	}
+
+	var hinted *Place
+	if hint != "" {
+		hinted = g.Resolve(hint, "")
+	}
+
+	// "Melbourne, Victoria, Australia" -> name "Melbourne", qualifiers "Victoria", "Australia"
+	parts := strings.Split(text, ",")
+	name := strings.TrimSpace(parts[0])
+	qualifiers := []string{}
+	for _, part := range parts[1:] {
+		if part = strings.TrimSpace(part); part != "" {
+			qualifiers = append(qualifiers, part)
+		}
+	}
+
+	// "Melbourne VIC" -> try the full name first, then move trailing words into qualifiers
+	words := strings.Fields(name)
+	for cut := len(words); cut > 0; cut-- {
+		candidates := g.byName[placeKey(strings.Join(words[:cut], " "))]
+		extra := append(append([]string{}, words[cut:]...), qualifiers...)
+
+		if hinted != nil {
+			candidates = inCountry(candidates, hinted.CountryCode)
+			extra = append(extra, hinted.Region)
+		}
+
+		if len(candidates) == 0 {
+			continue
+		}
+		return g.best(candidates, extra)
+	}
+
+	return nil
+}
+
+// best picks the candidate matching the most qualifiers, then the most populous
+func (g *Gazetteer) best(candidates []*Place, qualifiers []string) *Place {
+	var best *Place
+	bestScore := -1
+
+	for _, place := range candidates {
+		score := 0
+		for _, qualifier := range qualifiers {
+			if matchesQualifier(place, qualifier) {
+				score++
+			}
+		}
+
+		if score > bestScore || (score == bestScore && place.Population > best.Population) {
+			best = place
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// inCountry filters candidates to a single country
+func inCountry(candidates []*Place, countryCode string) []*Place {
+	filtered := []*Place{}
+	for _, place := range candidates {
+		if place.CountryCode == countryCode {
+			filtered = append(filtered, place)
+		}
+	}
+	return filtered
+}
+
+// matchesQualifier reports whether a qualifier names the place's region or country,
+// either in full ("Victoria"), as a code ("AU", "FL") or as an abbreviation ("VIC", "NSW")
+func matchesQualifier(place *Place, qualifier string) bool {
+	q := placeKey(qualifier)
+	if q == "" {
+		return false
+	}
+
+	for _, name := range []string{place.Region, place.RegionCode, place.Country, place.CountryCode} {
+		key := placeKey(name)
+		if key == "" {
+			continue
+		}
+		if key == q || strings.Contains(" "+q+" ", " "+key+" ") || abbreviates(q, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// abbreviates reports whether short is the initials of long ("nsw" / "new south wales")
+// or a prefix of at least three letters ("vic" / "victoria")
+func abbreviates(short, long string) bool {
+	if len(short) >= 3 && strings.HasPrefix(long, short) {
+		return true
+	}
+
+	initials := ""
+	for _, word := range strings.Fields(long) {
+		initials += word[:1]
+	}
+	return len(short) >= 2 && initials == short
+}
+
+// accentFolder replaces common Latin accented letters with their ASCII base letter
+var accentFolder = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y",
+	"ß", "ss", "æ", "ae", "œ", "oe",
+)
+
+// placeKey lower-cases a place name and strips accents and punctuation
+// Example: "São Paulo" -> "sao paulo"
+func placeKey(name string) string {
+	folded := accentFolder.Replace(strings.ToLower(name))
+
+	folded = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, folded)
+
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// readTSV calls fn for every non-comment line of a tab-separated file
+func readTSV(path string, fn func(fields []string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(strings.Split(line, "\t"))
+	}
+
+	return scanner.Err()
+}
@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // NormalizePrice extracts numeric price from strings like "$120", "£150 AUD", "$1,234"
@@ -52,6 +54,31 @@ func NormalizeRating(raw string) float64 {
 	return rating
 }
 
+// reviewCountRegex matches "(123)" or "123 reviews" after a rating
+var reviewCountRegex = regexp.MustCompile(`\(([\d,]+)\)|([\d,]+)\s+reviews?`)
+
+// NormalizeReviewCount extracts the review count from rating strings like
+// "4.95 (123)" or "4.95 out of 5 average rating, 1,234 reviews"
+// Returns 0 for "New" listings or if parsing fails
+func NormalizeReviewCount(raw string) int {
+	match := reviewCountRegex.FindStringSubmatch(raw)
+	if match == nil {
+		return 0
+	}
+
+	digits := match[1]
+	if digits == "" {
+		digits = match[2]
+	}
+
+	count, err := strconv.Atoi(strings.ReplaceAll(digits, ",", ""))
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
+
 // ExtractNumber extracts first integer from string
 // Used for bedrooms, bathrooms, guests (e.g., "3 bedrooms" -> 3)
 func ExtractNumber(raw string) int {
@@ -81,3 +108,66 @@ func CleanText(text string) string {
 
 	return text
 }
+
+// propertyTypeAliases maps lower-cased card prefixes to canonical property types
+var propertyTypeAliases = map[string]string{
+	"entire home":               "Home",
+	"entire rental unit":        "Apartment",
+	"rental unit":               "Apartment",
+	"entire condo":              "Condo",
+	"entire loft":               "Loft",
+	"entire villa":              "Villa",
+	"entire cabin":              "Cabin",
+	"entire cottage":            "Cottage",
+	"entire townhouse":          "Townhouse",
+	"entire guest suite":        "Guest suite",
+	"entire guesthouse":         "Guesthouse",
+	"entire serviced apartment": "Serviced apartment",
+	"private room":              "Room",
+	"shared room":               "Shared room",
+	"hotel room":                "Hotel room",
+	"room":                      "Room",
+	"flat":                      "Apartment",
+	"apartment":                 "Apartment",
+	"condominium":               "Condo",
+	"house":                     "Home",
+}
+
+// ParsePropertyHeadline splits card headlines like "Loft in Richmond" into
+// a property type ("Loft") and a place ("Richmond").
+// Nested headlines such as "Private room in rental unit in Tokyo" use the first
+// segment as the type and the last segment as the place.
+// Returns empty strings for the parts that cannot be found.
+func ParsePropertyHeadline(raw string) (propertyType, place string) {
+	text := CleanText(raw)
+	if text == "" {
+		return "", ""
+	}
+
+	first := strings.Index(text, " in ")
+	if first == -1 {
+		return "", text
+	}
+
+	last := strings.LastIndex(text, " in ")
+	return NormalizePropertyType(text[:first]), strings.TrimSpace(text[last+len(" in "):])
+}
+
+// NormalizePropertyType maps a raw property type to its canonical form
+// Example: "Entire rental unit" -> "Apartment", "tiny home" -> "Tiny home"
+func NormalizePropertyType(raw string) string {
+	text := CleanText(raw)
+	if text == "" {
+		return ""
+	}
+
+	if canonical, ok := propertyTypeAliases[strings.ToLower(text)]; ok {
+		return canonical
+	}
+
+	if strings.HasPrefix(strings.ToLower(text), "entire ") {
+		text = text[len("entire "):]
+	}
+	first, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(first)) + strings.ToLower(text[size:])
+}
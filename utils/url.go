@@ -2,9 +2,14 @@ package utils
 
 import (
 	"net/url"
+	"regexp"
 	"strings"
 )
 
+// roomIDRegex matches the numeric room ID in every Airbnb listing URL variant:
+// /rooms/123, /rooms/plus/123, /luxury/listing/123, on any airbnb.* domain
+var roomIDRegex = regexp.MustCompile(`/(?:rooms(?:/plus)?|luxury/listing)/(\d+)`)
+
 // NormalizeURL extracts the base URL without query parameters
 // Example: https://www.airbnb.com/rooms/123?search_mode=... -> https://www.airbnb.com/rooms/123
 func NormalizeURL(rawURL string) string {
@@ -43,3 +48,19 @@ func extractRoomURL(rawURL string) string {
 	// Extract base URL, everything before "?"
 	return rawURL[:roomsIndex+queryIndex]
 }
+
+// ExtractListingID returns the numeric Airbnb room ID from any listing URL variant
+// Example: https://www.airbnb.co.uk/rooms/plus/123?adults=2 -> 123
+// Returns an empty string if the URL does not contain a room ID
+func ExtractListingID(rawURL string) string {
+	match := roomIDRegex.FindStringSubmatch(rawURL)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// CanonicalListingURL builds the canonical listing URL for a room ID
+func CanonicalListingURL(listingID string) string {
+	return "https://www.airbnb.com/rooms/" + listingID
+}